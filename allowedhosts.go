@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// isAllowedHost reports whether the host (without the port) is one of the allowed hosts,
+// every host is allowed if the list is empty.
+func isAllowedHost(host string, allowedHosts []string) bool {
+	if len(allowedHosts) == 0 {
+		return true
+	}
+	for _, allowed := range allowedHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostNotAllowedError is returned for the requests to a host which is not allowed.
+type hostNotAllowedError struct {
+	Host         string
+	AllowedHosts []string
+}
+
+func (e hostNotAllowedError) Error() string {
+	return fmt.Sprintf("host is not allowed: %s, allowed hosts: %s", e.Host, strings.Join(e.AllowedHosts, ","))
+}
+
+func isHostNotAllowedError(err error) bool {
+	var hostErr hostNotAllowedError
+	return errors.As(err, &hostErr)
+}
+
+// allowedHostsTransport rejects the requests to a host which is not allowed before sending them.
+type allowedHostsTransport struct {
+	next         http.RoundTripper
+	allowedHosts []string
+}
+
+func (t allowedHostsTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !isAllowedHost(request.URL.Hostname(), t.allowedHosts) {
+		return nil, hostNotAllowedError{Host: request.URL.Hostname(), AllowedHosts: t.allowedHosts}
+	}
+	return t.next.RoundTrip(request)
+}
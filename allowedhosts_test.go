@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsAllowedHost(t *testing.T) {
+	tests := []struct {
+		host         string
+		allowedHosts []string
+		want         bool
+	}{
+		{host: "rink.hockeyapp.net", want: true},
+		{host: "rink.hockeyapp.net", allowedHosts: []string{"api.appcenter.ms", "rink.hockeyapp.net"}, want: true},
+		{host: "Rink.HockeyApp.net", allowedHosts: []string{"rink.hockeyapp.net"}, want: true},
+		{host: "evil.example.com", allowedHosts: []string{"rink.hockeyapp.net"}, want: false},
+		{host: "sub.rink.hockeyapp.net", allowedHosts: []string{"rink.hockeyapp.net"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := isAllowedHost(tt.host, tt.allowedHosts); got != tt.want {
+				t.Errorf("isAllowedHost(%s, %v) = %v, want %v", tt.host, tt.allowedHosts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowedHostsTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	tests := []struct {
+		name         string
+		allowedHosts []string
+		wantErr      bool
+	}{
+		{name: "allowed", allowedHosts: []string{"127.0.0.1"}},
+		{name: "not allowed", allowedHosts: []string{"rink.hockeyapp.net"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: allowedHostsTransport{next: http.DefaultTransport, allowedHosts: tt.allowedHosts}}
+			response, err := client.Get(ts.URL)
+			if err == nil {
+				response.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if isHostNotAllowedError(err) != tt.wantErr {
+				t.Errorf("isHostNotAllowedError(%v) = %v, want %v", err, !tt.wantErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsHostNotAllowedError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "host not allowed", err: hostNotAllowedError{Host: "example.com"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("request failed: %w", hostNotAllowedError{Host: "example.com"}), want: true},
+		{name: "other error", err: fmt.Errorf("timeout"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isHostNotAllowedError(tt.err); got != tt.want {
+				t.Errorf("isHostNotAllowedError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsFatalError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "host not allowed", err: hostNotAllowedError{Host: "example.com"}, want: true},
+		{name: "deploy aborted", err: deployAbortedError{Err: errors.New("pre upload command failed")}, want: true},
+		{name: "wrapped deploy aborted", err: fmt.Errorf("upload failed: %w", deployAbortedError{Err: errors.New("rejected")}), want: true},
+		{name: "failed upload", err: statusCodeError{StatusCode: 500}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFatalError(tt.err); got != tt.want {
+				t.Errorf("isFatalError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/command"
+)
+
+// annotationContext identifies the annotation of the step, so a rerun replaces it.
+const annotationContext = "hockeyapp-deploy"
+
+const (
+	annotationStyleSuccess = "success"
+	annotationStyleError   = "error"
+)
+
+// annotationMarkdown returns the markdown message of the status: the install link on success, the error on failure.
+func annotationMarkdown(status StatusModel) string {
+	if status.Status != hockeyAppDeployStatusSuccess {
+		return fmt.Sprintf("**HockeyApp deploy failed:** %s", status.Error)
+	}
+
+	var b strings.Builder
+	b.WriteString("**HockeyApp deploy succeeded**")
+	if status.Version != "" {
+		fmt.Fprintf(&b, ": %s", status.Version)
+	}
+	if status.PublicURL != "" {
+		fmt.Fprintf(&b, "\n\n[Install](%s)", status.PublicURL)
+	}
+	if status.BuildURL != "" {
+		fmt.Fprintf(&b, "\n\n[Download](%s)", status.BuildURL)
+	}
+	return b.String()
+}
+
+// runAnnotationCommand runs the annotations command and returns its combined output,
+// it is a variable, so the tests can record the command instead of running it.
+var runAnnotationCommand = func(name string, args ...string) (string, error) {
+	return command.New(name, args...).RunAndReturnTrimmedCombinedOutput()
+}
+
+// emitAnnotation creates the Bitrise build annotation of the status with the annotations command,
+// failing to annotate only prints a warning.
+func emitAnnotation(status StatusModel) {
+	if !configs.EmitAnnotation {
+		return
+	}
+
+	style := annotationStyleSuccess
+	if status.Status != hockeyAppDeployStatusSuccess {
+		style = annotationStyleError
+	}
+	if out, err := runAnnotationCommand("bitrise", ":annotations", "annotate", annotationMarkdown(status), "--style", style, "--context", annotationContext); err != nil {
+		warnf("Failed to emit the build annotation, error: %v, output: %s", err, out)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnnotationMarkdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		status StatusModel
+		want   string
+	}{
+		{
+			name:   "failure",
+			status: StatusModel{Status: hockeyAppDeployStatusFailed, Error: "upload failed"},
+			want:   "**HockeyApp deploy failed:** upload failed",
+		},
+		{
+			name:   "success",
+			status: StatusModel{Status: hockeyAppDeployStatusSuccess, Version: "1.2.3 (42)", PublicURL: "https://install", BuildURL: "https://download"},
+			want:   "**HockeyApp deploy succeeded**: 1.2.3 (42)\n\n[Install](https://install)\n\n[Download](https://download)",
+		},
+		{
+			name:   "success without URLs",
+			status: StatusModel{Status: hockeyAppDeployStatusSuccess},
+			want:   "**HockeyApp deploy succeeded**",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := annotationMarkdown(tt.status); got != tt.want {
+				t.Errorf("annotationMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// recordAnnotationCommands replaces the annotations command with one recording its argv for the duration of the test.
+func recordAnnotationCommands(t *testing.T, err error) *[][]string {
+	t.Helper()
+	var commands [][]string
+	original := runAnnotationCommand
+	runAnnotationCommand = func(name string, args ...string) (string, error) {
+		commands = append(commands, append([]string{name}, args...))
+		return "", err
+	}
+	t.Cleanup(func() { runAnnotationCommand = original })
+	return &commands
+}
+
+func TestEmitAnnotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		emitAnnotation bool
+		status         StatusModel
+		want           [][]string
+	}{
+		{
+			name:   "disabled",
+			status: StatusModel{Status: hockeyAppDeployStatusSuccess},
+		},
+		{
+			name:           "success",
+			emitAnnotation: true,
+			status:         StatusModel{Status: hockeyAppDeployStatusSuccess, Version: "1.2.3 (42)", PublicURL: "https://install"},
+			want: [][]string{{
+				"bitrise", ":annotations", "annotate", "**HockeyApp deploy succeeded**: 1.2.3 (42)\n\n[Install](https://install)",
+				"--style", annotationStyleSuccess, "--context", annotationContext,
+			}},
+		},
+		{
+			name:           "failure",
+			emitAnnotation: true,
+			status:         StatusModel{Status: hockeyAppDeployStatusFailed, Error: "upload failed"},
+			want: [][]string{{
+				"bitrise", ":annotations", "annotate", "**HockeyApp deploy failed:** upload failed",
+				"--style", annotationStyleError, "--context", annotationContext,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{EmitAnnotation: tt.emitAnnotation})
+			commands := recordAnnotationCommands(t, nil)
+
+			emitAnnotation(tt.status)
+
+			if !reflect.DeepEqual(*commands, tt.want) {
+				t.Errorf("annotation commands = %q, want %q", *commands, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmitAnnotationFailure(t *testing.T) {
+	original := warnings
+	t.Cleanup(func() { warnings = original })
+	setConfigs(t, ConfigsModel{EmitAnnotation: true, LogLevel: logLevelError})
+	recordAnnotationCommands(t, errors.New("exit status 1"))
+	warnings = nil
+
+	emitAnnotation(StatusModel{Status: hockeyAppDeployStatusSuccess})
+
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q, want the failed annotation warning", warnings)
+	}
+}
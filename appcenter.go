@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	apiFlavorHockeyApp = "hockeyapp"
+	apiFlavorAppCenter = "appcenter"
+
+	appCenterReleasePublicURL = "https://install.appcenter.ms/users/%s/apps/%s/releases/%d"
+	appCenterReleaseConfigURL = "https://appcenter.ms/users/%s/apps/%s/distribute/releases/%d"
+
+	appCenterUploadedStatus = "uploadFinished"
+	appCenterReadyStatus    = "readyToBePublished"
+	appCenterErrorStatus    = "error"
+
+	appCenterPollInterval    = 2 * time.Second
+	appCenterMaxPollAttempts = 150
+
+	// appCenterDefaultDestination is the distribution group every App Center app has.
+	appCenterDefaultDestination = "Collaborators"
+)
+
+// appCenterAPIURL is a variable, so the tests can point it at a test server.
+var appCenterAPIURL = "https://api.appcenter.ms/v0.1"
+
+// AppCenterUploadModel ...
+type AppCenterUploadModel struct {
+	ID              string `json:"id"`
+	UploadDomain    string `json:"upload_domain"`
+	PackageAssetID  string `json:"package_asset_id"`
+	URLEncodedToken string `json:"url_encoded_token"`
+}
+
+// AppCenterMetadataModel ...
+type AppCenterMetadataModel struct {
+	ChunkSize int64 `json:"chunk_size"`
+	ChunkList []int `json:"chunk_list"`
+}
+
+// AppCenterUploadStatusModel ...
+type AppCenterUploadStatusModel struct {
+	UploadStatus      string `json:"upload_status"`
+	ErrorDetails      string `json:"error_details"`
+	ReleaseDistinctID int    `json:"release_distinct_id"`
+}
+
+// AppCenterReleaseModel ...
+type AppCenterReleaseModel struct {
+	ID          int    `json:"id"`
+	DownloadURL string `json:"download_url"`
+}
+
+// appCenterApp splits the App ID in `owner/app` format.
+func appCenterApp(appID string) (string, string, error) {
+	split := strings.Split(appID, "/")
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", "", fmt.Errorf("invalid App Center AppID: %s, it should be in `owner_name/app_name` format", appID)
+	}
+	return split[0], split[1], nil
+}
+
+// AppCenterDestinationModel ...
+type AppCenterDestinationModel struct {
+	Name string `json:"name"`
+}
+
+// isAppCenterAPIURL reports whether the URL is of the App Center API,
+// the upload_domain URLs of the chunk upload are authorized by their token query parameter.
+func isAppCenterAPIURL(requestURL string) bool {
+	return strings.HasPrefix(requestURL, appCenterAPIURL+"/")
+}
+
+// appCenterRequest sends the request and decodes the JSON response into out (if not nil), retrying it like the uploads.
+// The App Center API token, the idempotency key (if not empty) and the extra headers and query parameters
+// are only sent to the App Center API, not to the upload domain.
+func appCenterRequest(ctx context.Context, client *http.Client, method, requestURL string, body []byte, contentType, idempotencyKey string, out interface{}) error {
+	// The App Center requests update the same upload or release, so they can be repeated safely.
+	_, err := retryRequest(ctx, true, func() error {
+		if method != "GET" {
+			countAttempt()
+		}
+		return performAppCenterRequest(ctx, client, method, requestURL, body, contentType, idempotencyKey, out)
+	})
+	return err
+}
+
+func performAppCenterRequest(ctx context.Context, client *http.Client, method, requestURL string, body []byte, contentType, idempotencyKey string, out interface{}) error {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return err
+	}
+	if isAppCenterAPIURL(requestURL) {
+		setExtraHeaders(request)
+		setExtraQueryParams(request)
+		signRequest(request, time.Now())
+		request.Header.Set("X-API-Token", configs.APIToken)
+		if idempotencyKey != "" {
+			request.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+	}
+	request.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("Performing request failed, error: %w", err)
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+	setLastStatusCode(response.StatusCode)
+
+	contents, err := readResponseBody(response)
+	if err != nil {
+		return fmt.Errorf("Failed to read response body, error: %v", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		debugf("Response body: %s", contents)
+		return statusCodeError{StatusCode: response.StatusCode}
+	}
+	if out == nil || len(bytes.TrimSpace(contents)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(contents, out); err != nil {
+		return fmt.Errorf("Failed to parse response body, error: %v", err)
+	}
+	return nil
+}
+
+// appCenterIdempotencyKey returns the idempotency key of a step of the App Center upload protocol,
+// every step is a different request, so a deduplicating gateway does not replay the response of an other step.
+func appCenterIdempotencyKey(idempotencyKey, step string) string {
+	return idempotencyKey + "-" + step
+}
+
+// uploadAppCenterChunks uploads the file in the chunks requested by the upload service.
+func uploadAppCenterChunks(ctx context.Context, client *http.Client, upload AppCenterUploadModel, artifact ArtifactModel, size int64, reporter ProgressReporter) error {
+	metadataURL := fmt.Sprintf("%s/upload/set_metadata/%s?file_name=%s&file_size=%d&token=%s&content_type=%s",
+		upload.UploadDomain, upload.PackageAssetID, url.QueryEscape(filepath.Base(artifact.Path)), size, upload.URLEncodedToken, url.QueryEscape(fileContentType(artifact.Path)))
+	var metadata AppCenterMetadataModel
+	if err := appCenterRequest(ctx, client, "POST", metadataURL, nil, "", "", &metadata); err != nil {
+		return fmt.Errorf("failed to set upload metadata, error: %v", err)
+	}
+	if metadata.ChunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size: %d", metadata.ChunkSize)
+	}
+
+	f, err := os.Open(artifact.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			warnf("Failed to close file (%s), error: %v", artifact.Path, err)
+		}
+	}()
+
+	chunk := make([]byte, metadata.ChunkSize)
+	var sent int64
+	for _, blockNumber := range metadata.ChunkList {
+		n, err := f.ReadAt(chunk, int64(blockNumber-1)*metadata.ChunkSize)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		chunkURL := fmt.Sprintf("%s/upload/upload_chunk/%s?token=%s&block_number=%d", upload.UploadDomain, upload.PackageAssetID, upload.URLEncodedToken, blockNumber)
+		if err := appCenterRequest(ctx, client, "POST", chunkURL, chunk[:n], "application/octet-stream", "", nil); err != nil {
+			return fmt.Errorf("failed to upload chunk %d, error: %v", blockNumber, err)
+		}
+
+		sent += int64(n)
+		if reporter != nil {
+			reporter.OnUploadProgress(sent, size)
+		}
+	}
+
+	finishedURL := fmt.Sprintf("%s/upload/finished/%s?token=%s", upload.UploadDomain, upload.PackageAssetID, upload.URLEncodedToken)
+	if err := appCenterRequest(ctx, client, "POST", finishedURL, nil, "", "", nil); err != nil {
+		return fmt.Errorf("failed to finish the upload, error: %v", err)
+	}
+	return nil
+}
+
+// waitForAppCenterRelease polls the upload until the release is ready and returns its ID.
+func waitForAppCenterRelease(ctx context.Context, client *http.Client, uploadURL string) (int, error) {
+	for attempt := 0; attempt < appCenterMaxPollAttempts; attempt++ {
+		var status AppCenterUploadStatusModel
+		if err := appCenterRequest(ctx, client, "GET", uploadURL, nil, "", "", &status); err != nil {
+			return 0, err
+		}
+
+		switch status.UploadStatus {
+		case appCenterReadyStatus:
+			return status.ReleaseDistinctID, nil
+		case appCenterErrorStatus:
+			return 0, fmt.Errorf("upload processing failed: %s", status.ErrorDetails)
+		}
+
+		select {
+		case <-time.After(appCenterPollInterval):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+	return 0, fmt.Errorf("release is not ready after %d status checks", appCenterMaxPollAttempts)
+}
+
+// appCenterReleaseUpdate returns the release update request body: the release notes and,
+// if the Status is downloadable, the distribution groups the release is distributed to,
+// the testers are notified unless Notify is 0.
+func appCenterReleaseUpdate() map[string]interface{} {
+	update := map[string]interface{}{
+		"release_notes":    configs.releaseNotes(),
+		"mandatory_update": configs.Mandatory == "1",
+	}
+	if configs.Status != "2" {
+		printf("The release is not distributed, as status is not 2 (downloadable)")
+		return update
+	}
+
+	names := configs.DistributionGroupNames
+	if len(names) == 0 {
+		names = []string{appCenterDefaultDestination}
+	}
+	destinations := []AppCenterDestinationModel{}
+	for _, name := range names {
+		destinations = append(destinations, AppCenterDestinationModel{Name: name})
+	}
+	update["destinations"] = destinations
+	update["notify_testers"] = configs.Notify != "0"
+	printf("Distributing the release to: %s (notify testers: %v)", strings.Join(names, ", "), configs.Notify != "0")
+	return update
+}
+
+// deployToAppCenter uploads the artifact with the App Center release upload protocol:
+// init the upload, upload the chunks, commit the upload, then set the release notes and distribute the release.
+func deployToAppCenter(ctx context.Context, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	owner, app, err := appCenterApp(configs.AppID)
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	info, err := os.Stat(artifact.Path)
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+	if reporter != nil {
+		reporter.OnValidated(artifact)
+	}
+	if configs.MappingPath != "" {
+		warnf("Mapping upload is not supported with the %s API flavor, skipping: %s", apiFlavorAppCenter, configs.MappingPath)
+	}
+	if configs.Tags != "" || configs.AutoTagBuildNumber {
+		warnf("Tags are not supported with the %s API flavor, use distribution_group_names to restrict the release", apiFlavorAppCenter)
+	}
+
+	appURL := fmt.Sprintf("%s/apps/%s/%s", appCenterAPIURL, url.PathEscape(owner), url.PathEscape(app))
+	uploadStart := time.Now()
+
+	var upload AppCenterUploadModel
+	if err := appCenterRequest(ctx, client, "POST", appURL+"/uploads/releases", nil, "", appCenterIdempotencyKey(idempotencyKey, "init"), &upload); err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to init the release upload, error: %v", err)
+	}
+
+	if err := uploadAppCenterChunks(ctx, client, upload, artifact, info.Size(), reporter); err != nil {
+		return ResponseModel{}, err
+	}
+
+	uploadURL := fmt.Sprintf("%s/uploads/releases/%s", appURL, upload.ID)
+	body, err := json.Marshal(map[string]string{"upload_status": appCenterUploadedStatus})
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	if err := appCenterRequest(ctx, client, "PATCH", uploadURL, body, "application/json", appCenterIdempotencyKey(idempotencyKey, "commit"), nil); err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to commit the release upload, error: %v", err)
+	}
+	uploadStats := UploadStatsModel{Size: info.Size(), Duration: time.Since(uploadStart)}
+
+	releaseID, err := waitForAppCenterRelease(ctx, client, uploadURL)
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to wait for the release, error: %v", err)
+	}
+
+	body, err = json.Marshal(appCenterReleaseUpdate())
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	var release AppCenterReleaseModel
+	if err := appCenterRequest(ctx, client, "PATCH", fmt.Sprintf("%s/releases/%d", appURL, releaseID), body, "application/json", appCenterIdempotencyKey(idempotencyKey, "release"), &release); err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to update the release, error: %v", err)
+	}
+
+	responseModel := ResponseModel{
+		PublicURL:   fmt.Sprintf(appCenterReleasePublicURL, owner, app, releaseID),
+		BuildURL:    release.DownloadURL,
+		ConfigURL:   fmt.Sprintf(appCenterReleaseConfigURL, owner, app, releaseID),
+		UploadStats: uploadStats,
+	}
+	printf("Uploaded %s", uploadStats)
+	if reporter != nil {
+		reporter.OnComplete(responseModel)
+	}
+	return responseModel, nil
+}
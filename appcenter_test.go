@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// setAPIURL points the API URL at the test server for the duration of the test.
+func setAPIURL(t *testing.T, apiURL *string, value string) {
+	original := *apiURL
+	*apiURL = value
+	t.Cleanup(func() { *apiURL = original })
+}
+
+func TestAppCenterApp(t *testing.T) {
+	tests := []struct {
+		appID     string
+		wantOwner string
+		wantApp   string
+		wantErr   bool
+	}{
+		{appID: "owner/app", wantOwner: "owner", wantApp: "app"},
+		{appID: "app", wantErr: true},
+		{appID: "owner/", wantErr: true},
+		{appID: "/app", wantErr: true},
+		{appID: "owner/app/extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.appID, func(t *testing.T) {
+			owner, app, err := appCenterApp(tt.appID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("appCenterApp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owner != tt.wantOwner || app != tt.wantApp {
+				t.Errorf("appCenterApp() = %s, %s, want %s, %s", owner, app, tt.wantOwner, tt.wantApp)
+			}
+		})
+	}
+}
+
+func TestAppCenterReleaseUpdate(t *testing.T) {
+	tests := []struct {
+		name             string
+		configs          ConfigsModel
+		wantDestinations interface{}
+		wantNotify       interface{}
+	}{
+		{name: "not downloadable", configs: ConfigsModel{Status: "1", Notify: "1"}},
+		{name: "default destination", configs: ConfigsModel{Status: "2", Notify: "1"}, wantDestinations: []AppCenterDestinationModel{{Name: appCenterDefaultDestination}}, wantNotify: true},
+		{name: "distribution groups", configs: ConfigsModel{Status: "2", Notify: "0", DistributionGroupNames: []string{"QA", "Beta"}}, wantDestinations: []AppCenterDestinationModel{{Name: "QA"}, {Name: "Beta"}}, wantNotify: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, tt.configs)
+			update := appCenterReleaseUpdate()
+			if _, ok := update["release_notes"]; !ok {
+				t.Errorf("update = %v, want release_notes", update)
+			}
+			if update["mandatory_update"] != false {
+				t.Errorf("mandatory_update = %v, want false", update["mandatory_update"])
+			}
+			if !reflect.DeepEqual(update["destinations"], tt.wantDestinations) {
+				t.Errorf("destinations = %v, want %v", update["destinations"], tt.wantDestinations)
+			}
+			if update["notify_testers"] != tt.wantNotify {
+				t.Errorf("notify_testers = %v, want %v", update["notify_testers"], tt.wantNotify)
+			}
+		})
+	}
+}
+
+// appCenterServer fakes the App Center API (under /v0.1) and its upload domain (under /upload).
+type appCenterServer struct {
+	mutex        sync.Mutex
+	url          string
+	initFailures int
+	requests     []appCenterTestRequest
+	chunks       []byte
+	release      map[string]interface{}
+}
+
+type appCenterTestRequest struct {
+	method         string
+	path           string
+	token          string
+	idempotencyKey string
+}
+
+func (s *appCenterServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = append(s.requests, appCenterTestRequest{method: r.Method, path: r.URL.Path, token: r.Header.Get("X-API-Token"), idempotencyKey: r.Header.Get("Idempotency-Key")})
+	body, _ := io.ReadAll(r.Body)
+
+	switch path := r.URL.Path; {
+	case path == "/v0.1/apps/owner/app/uploads/releases":
+		if s.initFailures > 0 {
+			s.initFailures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"id": "upload-id", "upload_domain": "%s", "package_asset_id": "asset", "url_encoded_token": "upload-token"}`, s.url)
+	case path == "/upload/set_metadata/asset":
+		fmt.Fprint(w, `{"chunk_size": 4, "chunk_list": [1, 2, 3]}`)
+	case path == "/upload/upload_chunk/asset":
+		s.chunks = append(s.chunks, body...)
+	case path == "/upload/finished/asset":
+	case path == "/v0.1/apps/owner/app/uploads/releases/upload-id" && r.Method == "PATCH":
+	case path == "/v0.1/apps/owner/app/uploads/releases/upload-id":
+		fmt.Fprint(w, `{"upload_status": "readyToBePublished", "release_distinct_id": 7}`)
+	case path == "/v0.1/apps/owner/app/releases/7":
+		if err := json.Unmarshal(body, &s.release); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"id": 7, "download_url": "https://download/7"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestDeployToAppCenter(t *testing.T) {
+	setConfigs(t, ConfigsModel{AppID: "owner/app", APIToken: "token", Status: "2", Notify: "1", RetryCount: 1, DistributionGroupNames: []string{"QA"}})
+	server := &appCenterServer{initFailures: 1}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	server.url = ts.URL
+	setAPIURL(t, &appCenterAPIURL, ts.URL+"/v0.1")
+
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := os.WriteFile(apkPath, []byte("0123456789"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := deployToAppCenter(context.Background(), ArtifactModel{Type: artifactTypeAPK, Path: apkPath}, "key", nil)
+	if err != nil {
+		t.Fatalf("deployToAppCenter() error = %v", err)
+	}
+	if response.BuildURL != "https://download/7" || response.PublicURL != fmt.Sprintf(appCenterReleasePublicURL, "owner", "app", 7) {
+		t.Errorf("response = %+v, want the release 7 URLs", response)
+	}
+	if string(server.chunks) != "0123456789" {
+		t.Errorf("uploaded chunks = %q, want the file contents", server.chunks)
+	}
+
+	wantKeys := map[string]string{
+		"POST /v0.1/apps/owner/app/uploads/releases":            "key-init",
+		"PATCH /v0.1/apps/owner/app/uploads/releases/upload-id": "key-commit",
+		"PATCH /v0.1/apps/owner/app/releases/7":                 "key-release",
+	}
+	initRequests := 0
+	for _, r := range server.requests {
+		request := r.method + " " + r.path
+		if request == "POST /v0.1/apps/owner/app/uploads/releases" {
+			initRequests++
+		}
+		if strings.HasPrefix(r.path, "/v0.1/") && r.token != "token" {
+			t.Errorf("%s token = %q, want the API token", request, r.token)
+		}
+		if strings.HasPrefix(r.path, "/upload/") && (r.token != "" || r.idempotencyKey != "") {
+			t.Errorf("%s sent the API token or the idempotency key to the upload domain", request)
+		}
+		if r.idempotencyKey != wantKeys[request] {
+			t.Errorf("%s idempotency key = %q, want %q", request, r.idempotencyKey, wantKeys[request])
+		}
+	}
+	if initRequests != 2 {
+		t.Errorf("init requests = %d, want the failed init request to be retried", initRequests)
+	}
+
+	wantDestinations := []interface{}{map[string]interface{}{"name": "QA"}}
+	if !reflect.DeepEqual(server.release["destinations"], wantDestinations) || server.release["notify_testers"] != true {
+		t.Errorf("release update = %v, want the QA destination with notified testers", server.release)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// appIDRegexp matches the HockeyApp App ID format: 32 hexadecimal characters.
+var appIDRegexp = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// propertyValue returns the value of the key from a .properties formatted content.
+func propertyValue(content []byte, key string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		sep := strings.IndexAny(line, "=:")
+		if sep == -1 {
+			continue
+		}
+		if strings.TrimSpace(line[:sep]) == key {
+			return strings.TrimSpace(line[sep+1:]), true
+		}
+	}
+	return "", false
+}
+
+// jsonValue returns the string value of the top level key of a JSON object.
+func jsonValue(content []byte, key string) (string, bool, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(content, &object); err != nil {
+		return "", false, err
+	}
+	value, ok := object[key]
+	if !ok {
+		return "", false, nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return "", false, fmt.Errorf("value of %s is not a string", key)
+	}
+	return str, true, nil
+}
+
+// readAppID reads the App ID from the file at pth: the whole file content is the ID if key is empty,
+// otherwise the value of the key in the properties (or JSON, by extension) file.
+func readAppID(pth, key string) (string, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return "", fmt.Errorf("failed to read AppIDPath at: %s, error: %v", pth, err)
+	}
+
+	appID := strings.TrimSpace(string(content))
+	if key != "" {
+		var found bool
+		if strings.ToLower(filepath.Ext(pth)) == ".json" {
+			if appID, found, err = jsonValue(content, key); err != nil {
+				return "", fmt.Errorf("failed to parse AppIDPath at: %s, error: %v", pth, err)
+			}
+		} else {
+			appID, found = propertyValue(content, key)
+		}
+		if !found {
+			return "", fmt.Errorf("no %s key found in AppIDPath at: %s", key, pth)
+		}
+	}
+
+	if configs.APIFlavor != apiFlavorAppCenter && !appIDRegexp.MatchString(appID) {
+		return "", fmt.Errorf("invalid App ID read from: %s, it should be 32 hexadecimal characters", pth)
+	}
+	return appID, nil
+}
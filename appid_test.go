@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const testAppID = "0123456789abcdef0123456789ABCDEF"
+
+func TestPropertyValue(t *testing.T) {
+	content := []byte("# comment\n! comment\nhockeyapp.appId = " + testAppID + "\nother:value\nnovalue\n")
+	tests := []struct {
+		key       string
+		want      string
+		wantFound bool
+	}{
+		{key: "hockeyapp.appId", want: testAppID, wantFound: true},
+		{key: "other", want: "value", wantFound: true},
+		{key: "novalue"},
+		{key: "comment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, found := propertyValue(content, tt.key)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("propertyValue() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestReadAppID(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		pth := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(pth, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return pth
+	}
+	plain := write("app_id", testAppID+"\n")
+	properties := write("hockeyapp.properties", "appId="+testAppID)
+	jsonFile := write("hockeyapp.json", `{"appId": "`+testAppID+`", "number": 1}`)
+	invalid := write("invalid_app_id", "not-an-app-id")
+	appCenter := write("appcenter_app_id", "owner/app")
+
+	tests := []struct {
+		name      string
+		pth       string
+		key       string
+		apiFlavor string
+		want      string
+		wantErr   bool
+	}{
+		{name: "whole file", pth: plain, want: testAppID},
+		{name: "properties key", pth: properties, key: "appId", want: testAppID},
+		{name: "JSON key", pth: jsonFile, key: "appId", want: testAppID},
+		{name: "missing key", pth: properties, key: "missing", wantErr: true},
+		{name: "non string JSON value", pth: jsonFile, key: "number", wantErr: true},
+		{name: "invalid App ID", pth: invalid, wantErr: true},
+		{name: "App Center App ID", pth: appCenter, apiFlavor: apiFlavorAppCenter, want: "owner/app"},
+		{name: "missing file", pth: filepath.Join(dir, "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{APIFlavor: tt.apiFlavor})
+			got, err := readAppID(tt.pth, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readAppID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readAppID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
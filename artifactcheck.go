@@ -0,0 +1,94 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// checkZipIntegrity reads every entry of the zip file, so the CRC-32 checksums of the entries are verified.
+func checkZipIntegrity(pth string) error {
+	r, err := zip.OpenReader(pth)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			warnf("Failed to close %s, error: %v", pth, err)
+		}
+	}()
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s, error: %v", f.Name, err)
+		}
+		_, err = io.Copy(ioutil.Discard, rc)
+		if cerr := rc.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s, error: %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkArtifact checks that the artifact exists and is a valid zip file, and returns its SHA-256 checksum.
+func checkArtifact(artifact ArtifactModel) (string, error) {
+	if _, err := os.Stat(artifact.Path); err != nil {
+		return "", fmt.Errorf("%s: %v", artifact.Path, err)
+	}
+	if err := checkZipIntegrity(artifact.Path); err != nil {
+		return "", fmt.Errorf("%s is not a valid zip file: %v", artifact.Path, err)
+	}
+	checksum, err := fileSHA256(artifact.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate the checksum of %s: %v", artifact.Path, err)
+	}
+	return checksum, nil
+}
+
+// checkArtifacts checks the APK and AAB artifacts with at most concurrency workers,
+// it returns the checksums by path and every issue found.
+func checkArtifacts(artifacts []ArtifactModel, concurrency int) (map[string]string, error) {
+	checksums := make([]string, len(artifacts))
+	errs := make([]error, len(artifacts))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				checksums[i], errs[i] = checkArtifact(artifacts[i])
+			}
+		}()
+	}
+	for i, artifact := range artifacts {
+		if artifact.Type == artifactTypeMapping {
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var issues validationErrors
+	checksumsByPath := map[string]string{}
+	for i, artifact := range artifacts {
+		if errs[i] != nil {
+			issues = append(issues, errs[i])
+		} else if checksums[i] != "" {
+			checksumsByPath[artifact.Path] = checksums[i]
+		}
+	}
+	if len(issues) > 0 {
+		return checksumsByPath, issues
+	}
+	return checksumsByPath, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// corruptedZip returns the path of a copy of the zip file with a byte of the stored entry contents changed,
+// so the CRC-32 checksum of the entry does not match.
+func corruptedZip(t *testing.T) string {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	entry, err := w.CreateHeader(&zip.FileHeader{Name: "classes.dex", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entry.Write([]byte("dex contents")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pth := filepath.Join(t.TempDir(), "corrupted.apk")
+	data := bytes.Replace(b.Bytes(), []byte("dex contents"), []byte("dex_contents"), 1)
+	if err := ioutil.WriteFile(pth, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return pth
+}
+
+func TestCheckZipIntegrity(t *testing.T) {
+	tests := []struct {
+		name    string
+		pth     string
+		wantErr bool
+	}{
+		{name: "valid", pth: "testdata/app.apk"},
+		{name: "corrupted", pth: corruptedZip(t), wantErr: true},
+		{name: "not a zip", pth: "testdata/output-metadata.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkZipIntegrity(tt.pth); (err != nil) != tt.wantErr {
+				t.Errorf("checkZipIntegrity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckArtifacts(t *testing.T) {
+	checksum, err := fileSHA256("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		artifacts     []ArtifactModel
+		concurrency   int
+		wantChecksums map[string]string
+		wantIssues    int
+	}{
+		{
+			name:          "valid artifacts",
+			artifacts:     []ArtifactModel{{Type: artifactTypeAPK, Path: "testdata/app.apk"}, {Type: artifactTypeMapping, Path: "testdata/mapping.txt"}},
+			concurrency:   1,
+			wantChecksums: map[string]string{"testdata/app.apk": checksum},
+		},
+		{
+			name:          "every issue reported",
+			artifacts:     []ArtifactModel{{Type: artifactTypeAPK, Path: corruptedZip(t)}, {Type: artifactTypeAPK, Path: "testdata/app.apk"}, {Type: artifactTypeAPK, Path: "testdata/missing.apk"}},
+			concurrency:   2,
+			wantChecksums: map[string]string{"testdata/app.apk": checksum},
+			wantIssues:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksums, err := checkArtifacts(tt.artifacts, tt.concurrency)
+			issues, _ := err.(validationErrors)
+			if len(issues) != tt.wantIssues || (err != nil && issues == nil) {
+				t.Fatalf("checkArtifacts() error = %v, want %d issues", err, tt.wantIssues)
+			}
+			if len(checksums) != len(tt.wantChecksums) {
+				t.Fatalf("checkArtifacts() checksums = %v, want %v", checksums, tt.wantChecksums)
+			}
+			for pth, want := range tt.wantChecksums {
+				if checksums[pth] != want {
+					t.Errorf("checksum of %s = %s, want %s", pth, checksums[pth], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+// stdinPath is the ApkPath the artifact is read from the standard input.
+const stdinPath = "-"
+
+// downloadProgress logs the received bytes at every 10%, if the total size is known.
+type downloadProgress struct {
+	received   int64
+	total      int64
+	lastDecile int64
+}
+
+func (p *downloadProgress) Write(b []byte) (int, error) {
+	p.received += int64(len(b))
+	if p.total > 0 {
+		if decile := p.received * 10 / p.total; decile != p.lastDecile {
+			p.lastDecile = decile
+			printf(" downloaded %d%% (%d/%d bytes)", p.received*100/p.total, p.received, p.total)
+		}
+	}
+	return len(b), nil
+}
+
+// storeArtifact writes the APK read from r into a new temporary file, which is removed if the step is interrupted.
+// total is the expected size, -1 if unknown.
+func storeArtifact(r io.Reader, total int64) (string, error) {
+	f, err := ioutil.TempFile(tempDir(), "hockeyapp-artifact-*.apk")
+	if err != nil {
+		return "", err
+	}
+	registerTempPath(f.Name())
+
+	h := sha256.New()
+	progress := &downloadProgress{total: total}
+	head := &bytes.Buffer{}
+	written, err := io.Copy(io.MultiWriter(f, h, progress, &limitedWriter{w: head, n: len(zipMagic)}), r)
+	if err != nil {
+		return "", closeWithError(f, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	if total >= 0 && written != total {
+		return "", fmt.Errorf("incomplete artifact, received %d of %d bytes", written, total)
+	}
+	if written == 0 {
+		return "", fmt.Errorf("empty artifact")
+	}
+	if !bytes.HasPrefix(head.Bytes(), zipMagic) {
+		return "", fmt.Errorf("the artifact is not an APK (zip) file")
+	}
+	printf("Artifact stored at: %s (%d bytes, SHA-256: %s)", f.Name(), written, hex.EncodeToString(h.Sum(nil)))
+	return f.Name(), nil
+}
+
+// limitedWriter keeps only the first n bytes written to it.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(b []byte) (int, error) {
+	if l.n > 0 {
+		head := b
+		if len(head) > l.n {
+			head = head[:l.n]
+		}
+		if _, err := l.w.Write(head); err != nil {
+			return 0, err
+		}
+		l.n -= len(head)
+	}
+	return len(b), nil
+}
+
+// downloadArtifact downloads the APK from the URL into a temporary file,
+// the extra headers are for the HockeyApp API, so they are not sent to the artifact host.
+func downloadArtifact(ctx context.Context, client *http.Client, artifactURL string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", artifactURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("Performing request failed, error: %w", err)
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", statusCodeError{StatusCode: response.StatusCode}
+	}
+	return storeArtifact(response.Body, response.ContentLength)
+}
+
+// readArtifactFromStdin reads the APK from the standard input into a temporary file.
+func readArtifactFromStdin() (string, error) {
+	return storeArtifact(os.Stdin, -1)
+}
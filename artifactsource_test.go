@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStoreArtifact(t *testing.T) {
+	apk, err := ioutil.ReadFile("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		total   int64
+		wantErr bool
+	}{
+		{name: "APK", data: apk, total: int64(len(apk))},
+		{name: "unknown size", data: apk, total: -1},
+		{name: "incomplete", data: apk[:100], total: int64(len(apk)), wantErr: true},
+		{name: "empty", data: []byte{}, total: -1, wantErr: true},
+		{name: "not an APK", data: []byte("<html></html>"), total: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{TempDir: t.TempDir()})
+			pth, err := storeArtifact(bytes.NewReader(tt.data), tt.total)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("storeArtifact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.HasPrefix(pth, configs.TempDir) {
+				t.Errorf("storeArtifact() = %s, want a file in the TempDir (%s)", pth, configs.TempDir)
+			}
+			if stored, err := ioutil.ReadFile(pth); err != nil || !bytes.Equal(stored, tt.data) {
+				t.Errorf("stored artifact differs from the input, error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloadArtifact(t *testing.T) {
+	apk, err := ioutil.ReadFile("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "downloaded", status: 200},
+		{name: "not found", status: 404, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{TempDir: t.TempDir(), extraHeaders: http.Header{"X-Custom": {"value"}}})
+			var header string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Get("X-Custom")
+				w.WriteHeader(tt.status)
+				if _, err := w.Write(apk); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer ts.Close()
+
+			_, err := downloadArtifact(context.Background(), ts.Client(), ts.URL+"/app.apk")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("downloadArtifact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if header != "" {
+				t.Errorf("X-Custom header = %q, want the extra headers not to be sent to the artifact host", header)
+			}
+		})
+	}
+}
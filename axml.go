@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Android binary XML chunk types.
+const (
+	axmlChunkStringPool   = 0x0001
+	axmlChunkXML          = 0x0003
+	axmlChunkResourceMap  = 0x0180
+	axmlChunkStartElement = 0x0102
+
+	axmlStringPoolUTF8Flag = 1 << 8
+	axmlNoIndex            = 0xffffffff
+
+	axmlTypeReference = 0x01
+	axmlTypeString    = 0x03
+	axmlTypeIntDec    = 0x10
+	axmlTypeIntHex    = 0x11
+	axmlTypeBoolean   = 0x12
+)
+
+// axmlAttributeNames maps the android framework attribute resource IDs to their names,
+// used if the attribute names are stripped from the string pool.
+var axmlAttributeNames = map[uint32]string{
+	0x0101000f: "debuggable",
+	0x0101020c: "minSdkVersion",
+	0x0101021b: "versionCode",
+	0x0101021c: "versionName",
+}
+
+var errInvalidAXML = errors.New("invalid binary XML")
+
+// axmlElement is a start element of a binary XML document, the attributes are keyed by their local names.
+type axmlElement struct {
+	Name       string
+	Attributes map[string]string
+}
+
+type axmlParser struct {
+	data        []byte
+	strings     []string
+	resourceIDs []uint32
+}
+
+func (p *axmlParser) uint16(offset int) (uint16, error) {
+	if offset < 0 || offset+2 > len(p.data) {
+		return 0, errInvalidAXML
+	}
+	return binary.LittleEndian.Uint16(p.data[offset:]), nil
+}
+
+func (p *axmlParser) uint32(offset int) (uint32, error) {
+	if offset < 0 || offset+4 > len(p.data) {
+		return 0, errInvalidAXML
+	}
+	return binary.LittleEndian.Uint32(p.data[offset:]), nil
+}
+
+func (p *axmlParser) string(index uint32) string {
+	if index == axmlNoIndex || int(index) >= len(p.strings) {
+		return ""
+	}
+	return p.strings[index]
+}
+
+func (p *axmlParser) parseStringPool(chunk int) error {
+	headerSize, err := p.uint16(chunk + 2)
+	if err != nil {
+		return err
+	}
+	count, err := p.uint32(chunk + 8)
+	if err != nil {
+		return err
+	}
+	flags, err := p.uint32(chunk + 16)
+	if err != nil {
+		return err
+	}
+	stringsStart, err := p.uint32(chunk + 20)
+	if err != nil {
+		return err
+	}
+	if int(count) > len(p.data)/4 {
+		return errInvalidAXML
+	}
+
+	p.strings = make([]string, count)
+	for i := 0; i < int(count); i++ {
+		offset, err := p.uint32(chunk + int(headerSize) + i*4)
+		if err != nil {
+			return err
+		}
+		start := chunk + int(stringsStart) + int(offset)
+		if flags&axmlStringPoolUTF8Flag != 0 {
+			p.strings[i], err = p.utf8String(start)
+		} else {
+			p.strings[i], err = p.utf16String(start)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *axmlParser) utf8Length(offset int) (int, int, error) {
+	if offset < 0 || offset >= len(p.data) {
+		return 0, 0, errInvalidAXML
+	}
+	length := int(p.data[offset])
+	if length&0x80 == 0 {
+		return length, 1, nil
+	}
+	if offset+1 >= len(p.data) {
+		return 0, 0, errInvalidAXML
+	}
+	return (length&0x7f)<<8 | int(p.data[offset+1]), 2, nil
+}
+
+func (p *axmlParser) utf8String(offset int) (string, error) {
+	// UTF-16 length, then UTF-8 length
+	_, n, err := p.utf8Length(offset)
+	if err != nil {
+		return "", err
+	}
+	length, m, err := p.utf8Length(offset + n)
+	if err != nil {
+		return "", err
+	}
+	start := offset + n + m
+	if start+length > len(p.data) {
+		return "", errInvalidAXML
+	}
+	return string(p.data[start : start+length]), nil
+}
+
+func (p *axmlParser) utf16String(offset int) (string, error) {
+	length, err := p.uint16(offset)
+	if err != nil {
+		return "", err
+	}
+	start := offset + 2
+	n := int(length)
+	if length&0x8000 != 0 {
+		low, err := p.uint16(offset + 2)
+		if err != nil {
+			return "", err
+		}
+		n = int(length&0x7fff)<<16 | int(low)
+		start += 2
+	}
+	if start+n*2 > len(p.data) {
+		return "", errInvalidAXML
+	}
+
+	chars := make([]uint16, n)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(p.data[start+i*2:])
+	}
+	return string(utf16.Decode(chars)), nil
+}
+
+func (p *axmlParser) attributeValue(offset int) (string, error) {
+	rawValue, err := p.uint32(offset + 8)
+	if err != nil {
+		return "", err
+	}
+	if rawValue != axmlNoIndex {
+		return p.string(rawValue), nil
+	}
+
+	if offset+15 >= len(p.data) {
+		return "", errInvalidAXML
+	}
+	dataType := p.data[offset+15]
+	data, err := p.uint32(offset + 16)
+	if err != nil {
+		return "", err
+	}
+
+	switch dataType {
+	case axmlTypeString:
+		return p.string(data), nil
+	case axmlTypeIntDec:
+		return strconv.FormatInt(int64(int32(data)), 10), nil
+	case axmlTypeIntHex:
+		return fmt.Sprintf("0x%x", data), nil
+	case axmlTypeBoolean:
+		return strconv.FormatBool(data != 0), nil
+	case axmlTypeReference:
+		return fmt.Sprintf("@0x%08x", data), nil
+	default:
+		return strconv.FormatUint(uint64(data), 10), nil
+	}
+}
+
+func (p *axmlParser) parseStartElement(chunk int) (axmlElement, error) {
+	headerSize, err := p.uint16(chunk + 2)
+	if err != nil {
+		return axmlElement{}, err
+	}
+	body := chunk + int(headerSize)
+
+	name, err := p.uint32(body + 4)
+	if err != nil {
+		return axmlElement{}, err
+	}
+	attributeStart, err := p.uint16(body + 8)
+	if err != nil {
+		return axmlElement{}, err
+	}
+	attributeSize, err := p.uint16(body + 10)
+	if err != nil {
+		return axmlElement{}, err
+	}
+	attributeCount, err := p.uint16(body + 12)
+	if err != nil {
+		return axmlElement{}, err
+	}
+
+	element := axmlElement{Name: p.string(name), Attributes: map[string]string{}}
+	for i := 0; i < int(attributeCount); i++ {
+		offset := body + int(attributeStart) + i*int(attributeSize)
+		nameIndex, err := p.uint32(offset + 4)
+		if err != nil {
+			return axmlElement{}, err
+		}
+
+		attributeName := p.string(nameIndex)
+		if attributeName == "" && int(nameIndex) < len(p.resourceIDs) {
+			attributeName = axmlAttributeNames[p.resourceIDs[nameIndex]]
+		}
+		if attributeName == "" {
+			continue
+		}
+
+		value, err := p.attributeValue(offset)
+		if err != nil {
+			return axmlElement{}, err
+		}
+		element.Attributes[attributeName] = value
+	}
+	return element, nil
+}
+
+func (p *axmlParser) parseResourceMap(chunk int, size uint32) error {
+	headerSize, err := p.uint16(chunk + 2)
+	if err != nil {
+		return err
+	}
+	count := (int(size) - int(headerSize)) / 4
+	p.resourceIDs = make([]uint32, 0, count)
+	for i := 0; i < count; i++ {
+		id, err := p.uint32(chunk + int(headerSize) + i*4)
+		if err != nil {
+			return err
+		}
+		p.resourceIDs = append(p.resourceIDs, id)
+	}
+	return nil
+}
+
+// parseAXMLElements returns the start elements of the Android binary XML document in document order.
+func parseAXMLElements(data []byte) ([]axmlElement, error) {
+	p := &axmlParser{data: data}
+
+	xmlType, err := p.uint16(0)
+	if err != nil {
+		return nil, err
+	}
+	if xmlType != axmlChunkXML {
+		return nil, errInvalidAXML
+	}
+	headerSize, err := p.uint16(2)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := []axmlElement{}
+	for chunk := int(headerSize); chunk+8 <= len(data); {
+		chunkType, err := p.uint16(chunk)
+		if err != nil {
+			return nil, err
+		}
+		size, err := p.uint32(chunk + 4)
+		if err != nil {
+			return nil, err
+		}
+		if size < 8 {
+			return nil, errInvalidAXML
+		}
+
+		switch chunkType {
+		case axmlChunkStringPool:
+			if err := p.parseStringPool(chunk); err != nil {
+				return nil, err
+			}
+		case axmlChunkResourceMap:
+			if err := p.parseResourceMap(chunk, size); err != nil {
+				return nil, err
+			}
+		case axmlChunkStartElement:
+			element, err := p.parseStartElement(chunk)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		}
+
+		chunk += int(size)
+	}
+	return elements, nil
+}
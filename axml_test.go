@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseAXMLElements(t *testing.T) {
+	manifest := readZipEntry(t, "testdata/app.apk", apkManifestPath)
+	tests := []struct {
+		name      string
+		data      []byte
+		wantNames []string
+		wantErr   bool
+	}{
+		{name: "manifest", data: manifest, wantNames: []string{"manifest", "uses-sdk", "application"}},
+		{name: "empty", data: []byte{}, wantErr: true},
+		{name: "not binary XML", data: []byte("<manifest/>"), wantErr: true},
+		{name: "truncated", data: manifest[:len(manifest)/2], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := parseAXMLElements(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAXMLElements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(elements) != len(tt.wantNames) {
+				t.Fatalf("parseAXMLElements() = %d elements, want %d", len(elements), len(tt.wantNames))
+			}
+			for i, element := range elements {
+				if element.Name != tt.wantNames[i] {
+					t.Errorf("element %d = %s, want %s", i, element.Name, tt.wantNames[i])
+				}
+			}
+		})
+	}
+}
+
+// axmlTestAttribute is an attribute of the start element built by buildAXML.
+type axmlTestAttribute struct {
+	name     uint32
+	rawValue uint32
+	dataType byte
+	data     uint32
+}
+
+// buildAXML builds a binary XML document of a single "manifest" start element (string index 0).
+func buildAXML(utf8 bool, pool []string, resourceIDs []uint32, attributes []axmlTestAttribute) []byte {
+	write := func(b *bytes.Buffer, values ...interface{}) {
+		for _, value := range values {
+			_ = binary.Write(b, binary.LittleEndian, value)
+		}
+	}
+
+	var stringData bytes.Buffer
+	var offsets []uint32
+	for _, s := range pool {
+		offsets = append(offsets, uint32(stringData.Len()))
+		if utf8 {
+			write(&stringData, uint8(len(utf16.Encode([]rune(s)))), uint8(len(s)), []byte(s), uint8(0))
+		} else {
+			chars := utf16.Encode([]rune(s))
+			write(&stringData, uint16(len(chars)), chars, uint16(0))
+		}
+	}
+	for stringData.Len()%4 != 0 {
+		stringData.WriteByte(0)
+	}
+	var flags uint32
+	if utf8 {
+		flags = axmlStringPoolUTF8Flag
+	}
+	var stringPool bytes.Buffer
+	headerSize := uint32(28 + 4*len(pool))
+	write(&stringPool, uint16(axmlChunkStringPool), uint16(28), headerSize+uint32(stringData.Len()), uint32(len(pool)), uint32(0), flags, headerSize, uint32(0), offsets, stringData.Bytes())
+
+	var resourceMap bytes.Buffer
+	write(&resourceMap, uint16(axmlChunkResourceMap), uint16(8), uint32(8+4*len(resourceIDs)), resourceIDs)
+
+	var element bytes.Buffer
+	write(&element, uint16(axmlChunkStartElement), uint16(16), uint32(16+20+20*len(attributes)), uint32(1), uint32(axmlNoIndex))
+	write(&element, uint32(axmlNoIndex), uint32(0), uint16(20), uint16(20), uint16(len(attributes)), uint16(0), uint16(0), uint16(0))
+	for _, attribute := range attributes {
+		write(&element, uint32(axmlNoIndex), attribute.name, attribute.rawValue, uint16(8), uint8(0), attribute.dataType, attribute.data)
+	}
+
+	var document bytes.Buffer
+	write(&document, uint16(axmlChunkXML), uint16(8), uint32(8+stringPool.Len()+resourceMap.Len()+element.Len()))
+	document.Write(stringPool.Bytes())
+	document.Write(resourceMap.Bytes())
+	document.Write(element.Bytes())
+	return document.Bytes()
+}
+
+func TestParseAXMLAttributes(t *testing.T) {
+	pool := []string{"manifest", "package", "versionName", "", "com.example.\u00e4pp", "1.2.3"}
+	attributes := []axmlTestAttribute{
+		{name: 1, rawValue: 4, dataType: axmlTypeString, data: 4},
+		{name: 2, rawValue: axmlNoIndex, dataType: axmlTypeString, data: 5},
+		{name: 3, rawValue: axmlNoIndex, dataType: axmlTypeIntDec, data: 42},
+	}
+	tests := []struct {
+		name        string
+		utf8        bool
+		resourceIDs []uint32
+		attributes  []axmlTestAttribute
+		want        map[string]string
+	}{
+		{
+			name:        "UTF-16 string pool",
+			resourceIDs: []uint32{0, 0, 0, 0x0101021b},
+			attributes:  attributes,
+			want:        map[string]string{"package": "com.example.\u00e4pp", "versionName": "1.2.3", "versionCode": "42"},
+		},
+		{
+			name:        "UTF-8 string pool",
+			utf8:        true,
+			resourceIDs: []uint32{0, 0, 0, 0x0101021b},
+			attributes:  attributes,
+			want:        map[string]string{"package": "com.example.\u00e4pp", "versionName": "1.2.3", "versionCode": "42"},
+		},
+		{
+			name:       "stripped name without resource ID",
+			attributes: attributes[2:],
+			want:       map[string]string{},
+		},
+		{
+			name:        "typed values",
+			resourceIDs: []uint32{0, 0, 0, 0x0101000f},
+			attributes: []axmlTestAttribute{
+				{name: 3, rawValue: axmlNoIndex, dataType: axmlTypeBoolean, data: 0xffffffff},
+				{name: 1, rawValue: axmlNoIndex, dataType: axmlTypeIntHex, data: 0x1f},
+				{name: 2, rawValue: axmlNoIndex, dataType: axmlTypeReference, data: 0x7f040001},
+			},
+			want: map[string]string{"debuggable": "true", "package": "0x1f", "versionName": "@0x7f040001"},
+		},
+		{
+			name:       "negative integer",
+			attributes: []axmlTestAttribute{{name: 2, rawValue: axmlNoIndex, dataType: axmlTypeIntDec, data: 0xffffffff}},
+			want:       map[string]string{"versionName": "-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elements, err := parseAXMLElements(buildAXML(tt.utf8, pool, tt.resourceIDs, tt.attributes))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(elements) != 1 || elements[0].Name != "manifest" {
+				t.Fatalf("parseAXMLElements() = %+v, want the manifest element", elements)
+			}
+			if got := elements[0].Attributes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attributes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
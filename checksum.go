@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// serverChecksumHeader is the response header the gateway returns the SHA-256 checksum of the received artifact in.
+const serverChecksumHeader = "X-Checksum-Sha256"
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of the file.
+func fileSHA256(pth string) (string, error) {
+	f, err := os.Open(pth)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			warnf("Failed to close file (%s), error: %v", pth, err)
+		}
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyServerChecksum compares the checksum returned by the server with the checksum of the uploaded artifact,
+// the checksum is calculated from the file if localChecksum is empty.
+func verifyServerChecksum(response *http.Response, artifact ArtifactModel, localChecksum string) error {
+	serverChecksum := strings.ToLower(strings.TrimSpace(response.Header.Get(serverChecksumHeader)))
+	if serverChecksum == "" {
+		err := fmt.Errorf("no %s header in the response, the upload integrity can not be verified", serverChecksumHeader)
+		if configs.StrictMode {
+			return err
+		}
+		warnf("%s", err)
+		return nil
+	}
+
+	if localChecksum == "" {
+		var err error
+		if localChecksum, err = fileSHA256(artifact.Path); err != nil {
+			return fmt.Errorf("failed to calculate the checksum of %s, error: %v", artifact.Path, err)
+		}
+	}
+	if serverChecksum != localChecksum {
+		return fmt.Errorf("checksum mismatch: the server received %s (SHA-256: %s), but the local file's SHA-256 is %s", artifact.Path, serverChecksum, localChecksum)
+	}
+	printf("Server checksum verified: %s", localChecksum)
+	return nil
+}
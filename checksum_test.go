@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestVerifyServerChecksum(t *testing.T) {
+	checksum, err := fileSHA256("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact := ArtifactModel{Type: artifactTypeAPK, Path: "testdata/app.apk"}
+	tests := []struct {
+		name           string
+		serverChecksum string
+		localChecksum  string
+		strictMode     bool
+		wantErr        bool
+	}{
+		{name: "match", serverChecksum: checksum},
+		{name: "match with the calculated checksum", serverChecksum: checksum, localChecksum: checksum},
+		{name: "case and whitespace insensitive", serverChecksum: " " + strings.ToUpper(checksum) + " "},
+		{name: "mismatch", serverChecksum: "00" + checksum[2:], wantErr: true},
+		{name: "no header", serverChecksum: ""},
+		{name: "no header in strict mode", serverChecksum: "", strictMode: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{StrictMode: tt.strictMode})
+			response := &http.Response{Header: http.Header{}}
+			if tt.serverChecksum != "" {
+				response.Header.Set(serverChecksumHeader, tt.serverChecksum)
+			}
+			if err := verifyServerChecksum(response, artifact, tt.localChecksum); (err != nil) != tt.wantErr {
+				t.Errorf("verifyServerChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileSHA256(t *testing.T) {
+	tests := []struct {
+		name    string
+		pth     string
+		wantErr bool
+	}{
+		{name: "file", pth: "testdata/output-metadata.json"},
+		{name: "missing", pth: "testdata/missing.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileSHA256(tt.pth)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fileSHA256() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got) != 64 {
+				t.Errorf("fileSHA256() = %s, want a hex encoded SHA-256 checksum", got)
+			}
+		})
+	}
+}
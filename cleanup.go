@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+const interruptExitCode = 130
+
+// tempPaths are the temporary files and directories to remove if the step is interrupted.
+var tempPaths = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: map[string]bool{}}
+
+func registerTempPath(pth string) {
+	tempPaths.Lock()
+	defer tempPaths.Unlock()
+	tempPaths.paths[pth] = true
+}
+
+func unregisterTempPath(pth string) {
+	tempPaths.Lock()
+	defer tempPaths.Unlock()
+	delete(tempPaths.paths, pth)
+}
+
+// removeTempPaths removes every registered temporary file and directory, and returns how many were removed.
+func removeTempPaths() int {
+	tempPaths.Lock()
+	defer tempPaths.Unlock()
+
+	removed := 0
+	for pth := range tempPaths.paths {
+		if err := os.RemoveAll(pth); err != nil {
+			warnf("Failed to remove temporary path (%s), error: %v", pth, err)
+			continue
+		}
+		delete(tempPaths.paths, pth)
+		removed++
+	}
+	return removed
+}
+
+// cleanupOnInterrupt removes the registered temporary files before exiting, if the step receives SIGINT or SIGTERM.
+func cleanupOnInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go handleInterrupt(signals, os.Exit)
+}
+
+// handleInterrupt waits for a signal, then removes the registered temporary files and exits with the interrupt exit code.
+func handleInterrupt(signals <-chan os.Signal, exit func(code int)) {
+	sig := <-signals
+	noticef("Step interrupted (%s), cleaning up", sig)
+	printf("Removed %d temporary file(s)", removeTempPaths())
+	exit(interruptExitCode)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// resetTempPaths clears the registered temporary paths for the duration of the test.
+func resetTempPaths(t *testing.T) {
+	t.Helper()
+	tempPaths.Lock()
+	original := tempPaths.paths
+	tempPaths.paths = map[string]bool{}
+	tempPaths.Unlock()
+	t.Cleanup(func() {
+		tempPaths.Lock()
+		tempPaths.paths = original
+		tempPaths.Unlock()
+	})
+}
+
+func TestRemoveTempPaths(t *testing.T) {
+	resetTempPaths(t)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "artifact.apk")
+	nested := filepath.Join(dir, "package")
+	kept := filepath.Join(dir, "kept.apk")
+	for _, pth := range []string{file, kept, filepath.Join(nested, "app.apk")} {
+		if err := os.MkdirAll(filepath.Dir(pth), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(pth, []byte("apk"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registerTempPath(file)
+	registerTempPath(nested)
+	registerTempPath(kept)
+	unregisterTempPath(kept)
+
+	if removed := removeTempPaths(); removed != 2 {
+		t.Errorf("removeTempPaths() = %d, want 2", removed)
+	}
+	tests := []struct {
+		pth        string
+		wantExists bool
+	}{
+		{pth: file},
+		{pth: nested},
+		{pth: kept, wantExists: true},
+	}
+	for _, tt := range tests {
+		if _, err := os.Stat(tt.pth); (err == nil) != tt.wantExists {
+			t.Errorf("%s exists: %v, want %v", tt.pth, err == nil, tt.wantExists)
+		}
+	}
+	if removed := removeTempPaths(); removed != 0 {
+		t.Errorf("removeTempPaths() = %d after the cleanup, want 0", removed)
+	}
+}
+
+func TestHandleInterrupt(t *testing.T) {
+	resetTempPaths(t)
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "app.apk"), filepath.Join(dir, "mapping.txt.gz")}
+	for _, pth := range files {
+		if err := ioutil.WriteFile(pth, []byte("temp"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		registerTempPath(pth)
+	}
+
+	signals := make(chan os.Signal, 1)
+	exitCodes := make(chan int, 1)
+	go handleInterrupt(signals, func(code int) { exitCodes <- code })
+	signals <- os.Interrupt
+
+	select {
+	case code := <-exitCodes:
+		if code != interruptExitCode {
+			t.Errorf("exit code = %d, want %d", code, interruptExitCode)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handleInterrupt() did not exit on the interrupt")
+	}
+	for _, pth := range files {
+		if _, err := os.Stat(pth); !os.IsNotExist(err) {
+			t.Errorf("%s is not removed, error: %v", pth, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Defaults of http.DefaultTransport.
+const (
+	defaultConnectTimeout      = 30 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+func newHTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	dialer := &net.Dialer{Timeout: defaultConnectTimeout, KeepAlive: defaultKeepAlive}
+	if configs.ConnectTimeout > 0 {
+		dialer.Timeout = configs.ConnectTimeout
+	}
+	transport.DialContext = dialer.DialContext
+	if configs.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = configs.TLSHandshakeTimeout
+	}
+	if configs.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = configs.IdleConnTimeout
+	}
+	transport.DisableKeepAlives = configs.DisableKeepAlive
+
+	if configs.UnixSocketPath != "" {
+		transport.DialContext = unixSocketDialer(dialer, configs.UnixSocketPath)
+	} else if configs.CacheDNS {
+		dnsCache = newCachingDialer(dialer.DialContext)
+		transport.DialContext = dnsCache.DialContext
+	}
+	if configs.ProxyURL != "" {
+		proxyURL, err := url.Parse(configs.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = proxyFunc(proxyURL, configs.NoProxy)
+	}
+	if configs.CACertPath != "" || configs.TLSMinVersion != "" {
+		tlsConfig := &tls.Config{MinVersion: tlsVersions[configs.TLSMinVersion]}
+		if configs.CACertPath != "" {
+			rootCAs, err := loadCACertPool(configs.CACertPath)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = rootCAs
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	var roundTripper http.RoundTripper = transport
+	if configs.TraceOutputPath != "" {
+		roundTripper = tracingTransport{next: roundTripper}
+	}
+	if len(configs.AllowedHosts) > 0 {
+		roundTripper = allowedHostsTransport{next: roundTripper, allowedHosts: configs.AllowedHosts}
+	}
+	return &http.Client{Transport: roundTripper}, nil
+}
+
+var (
+	sharedClient     *http.Client
+	sharedClientErr  error
+	sharedClientOnce sync.Once
+)
+
+// sharedHTTPClient returns the client used by every request of the step,
+// so the uploads can reuse the open connections.
+func sharedHTTPClient() (*http.Client, error) {
+	sharedClientOnce.Do(func() {
+		sharedClient, sharedClientErr = newHTTPClient()
+	})
+	return sharedClient, sharedClientErr
+}
+
+// warmUpConnection sends a HEAD request to the API host, so the TLS connection is established
+// (and kept alive in the client's pool) before the upload starts.
+func warmUpConnection(ctx context.Context, client *http.Client, requestURL string) error {
+	request, err := http.NewRequestWithContext(ctx, "HEAD", requestURL, nil)
+	if err != nil {
+		return err
+	}
+	setExtraHeaders(request)
+	setExtraQueryParams(request)
+
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(ioutil.Discard, response.Body); err != nil {
+		return err
+	}
+	if err := response.Body.Close(); err != nil {
+		return err
+	}
+	printf("Connection warmed up in %s (status code: %d)", time.Since(start).Round(time.Millisecond), response.StatusCode)
+	return nil
+}
+
+// loadCACertPool returns the system cert pool extended with the PEM encoded certificates of the file.
+func loadCACertPool(pth string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate bundle at: %s, error: %v", pth, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		warnf("Failed to load the system cert pool, using only the provided CA certificates: %v", err)
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no valid PEM encoded certificate found in the CA certificate bundle at: %s", pth)
+	}
+	return pool, nil
+}
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// unixSocketDialer connects to the unix socket at pth regardless of the requested address,
+// the requests are sent as if they were sent to the original host.
+func unixSocketDialer(dialer *net.Dialer, pth string) dialFunc {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", pth)
+	}
+}
+
+// cachingDialer resolves every host only once and dials the cached IPs afterwards,
+// so a flaky DNS can only fail the first attempt.
+type cachingDialer struct {
+	dial       dialFunc
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+
+	mu  sync.Mutex
+	ips map[string][]string
+}
+
+// dnsCache is the caching dialer of the shared client if CacheDNS is enabled.
+var dnsCache *cachingDialer
+
+func newCachingDialer(dial dialFunc) *cachingDialer {
+	return &cachingDialer{dial: dial, lookupHost: net.DefaultResolver.LookupHost, ips: map[string][]string{}}
+}
+
+// resolve returns the cached IPs of the host, or looks them up. The lock is not held during the lookup,
+// so the parallel uploads do not wait for each other's lookups, the first stored result is kept.
+func (d *cachingDialer) resolve(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	ips, ok := d.ips[host]
+	d.mu.Unlock()
+	if ok {
+		return ips, nil
+	}
+
+	ips, err := d.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if cached, ok := d.ips[host]; ok {
+		return cached, nil
+	}
+	debugf("Resolved %s to %v", host, ips)
+	d.ips[host] = ips
+	return ips, nil
+}
+
+// resolveUpFront resolves the host before the uploads start, retrying the transient DNS failures,
+// so every upload attempt dials the cached IPs.
+func (d *cachingDialer) resolveUpFront(ctx context.Context, host string) error {
+	_, err := retryRequest(ctx, true, func() error {
+		_, err := d.resolve(ctx, host)
+		return err
+	})
+	return err
+}
+
+func (d *cachingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) != nil {
+		return d.dial(ctx, network, address)
+	}
+
+	ips, err := d.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	err = &net.DNSError{Err: "no addresses found", Name: host}
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = d.dial(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
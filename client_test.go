@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	caCertPath := filepath.Join(dir, "ca.pem")
+	if err := ioutil.WriteFile(caCertPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		configs    ConfigsModel
+		wantErr    bool
+		wantGetErr bool
+	}{
+		{name: "untrusted server certificate", configs: ConfigsModel{}, wantGetErr: true},
+		{name: "trusted CA certificate", configs: ConfigsModel{CACertPath: caCertPath}},
+		{name: "minimum TLS version", configs: ConfigsModel{CACertPath: caCertPath, TLSMinVersion: "1.3"}},
+		{name: "not allowed host", configs: ConfigsModel{CACertPath: caCertPath, AllowedHosts: []string{"rink.hockeyapp.net"}}, wantGetErr: true},
+		{name: "missing CA certificate", configs: ConfigsModel{CACertPath: filepath.Join(dir, "missing.pem")}, wantErr: true},
+		{name: "invalid CA certificate", configs: ConfigsModel{CACertPath: "testdata/output-metadata.json"}, wantErr: true},
+		{name: "invalid proxy URL", configs: ConfigsModel{ProxyURL: "://proxy"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, tt.configs)
+			client, err := newHTTPClient()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newHTTPClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			response, err := client.Get(ts.URL)
+			if err == nil {
+				response.Body.Close()
+			}
+			if (err != nil) != tt.wantGetErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantGetErr)
+			}
+		})
+	}
+}
+
+func TestWarmUpConnection(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "ok", status: 200},
+		{name: "any status code", status: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{})
+			var method string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			if err := warmUpConnection(context.Background(), ts.Client(), ts.URL); (err != nil) != tt.wantErr {
+				t.Fatalf("warmUpConnection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if method != "HEAD" {
+				t.Errorf("method = %s, want HEAD", method)
+			}
+		})
+	}
+}
+
+func TestCachingDialer(t *testing.T) {
+	errRefused := errors.New("connection refused")
+	tests := []struct {
+		name      string
+		address   string
+		cached    map[string][]string
+		failing   []string
+		wantDials []string
+		wantErr   bool
+	}{
+		{name: "IP address", address: "127.0.0.1:443", wantDials: []string{"127.0.0.1:443"}},
+		{name: "cached host", address: "api.example.com:443", cached: map[string][]string{"api.example.com": {"10.0.0.1"}}, wantDials: []string{"10.0.0.1:443"}},
+		{name: "next IP on failure", address: "api.example.com:443", cached: map[string][]string{"api.example.com": {"10.0.0.1", "10.0.0.2"}}, failing: []string{"10.0.0.1:443"}, wantDials: []string{"10.0.0.1:443", "10.0.0.2:443"}},
+		{name: "every IP fails", address: "api.example.com:443", cached: map[string][]string{"api.example.com": {"10.0.0.1"}}, failing: []string{"10.0.0.1:443"}, wantDials: []string{"10.0.0.1:443"}, wantErr: true},
+		{name: "no addresses", address: "api.example.com:443", cached: map[string][]string{"api.example.com": {}}, wantErr: true},
+		{name: "invalid address", address: "api.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dials []string
+			d := newCachingDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+				dials = append(dials, address)
+				for _, failing := range tt.failing {
+					if address == failing {
+						return nil, errRefused
+					}
+				}
+				client, server := net.Pipe()
+				server.Close()
+				return client, nil
+			})
+			for host, ips := range tt.cached {
+				d.ips[host] = ips
+			}
+
+			conn, err := d.DialContext(context.Background(), "tcp", tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DialContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if conn != nil {
+				conn.Close()
+			}
+			if !reflect.DeepEqual(dials, tt.wantDials) {
+				t.Errorf("dialed = %v, want %v", dials, tt.wantDials)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "api.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var host, ipa string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		if file, header, err := r.FormFile("ipa"); err == nil {
+			file.Close()
+			ipa = filepath.Base(header.Filename)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := ioutil.WriteFile(apkPath, []byte("apk"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setConfigs(t, ConfigsModel{UnixSocketPath: socketPath})
+	client, err := newHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _, err := createRequest("POST", "http://rink.hockeyapp.net/api/2/apps/upload", map[string]string{"status": "2"}, map[string]string{"ipa": apkPath}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("upload through the unix socket failed: %v", err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated || host != "rink.hockeyapp.net" || ipa != "app.apk" {
+		t.Errorf("upload = status %d, host %s, ipa %s, want the APK uploaded to the socket as rink.hockeyapp.net", response.StatusCode, host, ipa)
+	}
+}
+
+func TestNewHTTPClientTimeouts(t *testing.T) {
+	const timeout = 300 * time.Millisecond
+
+	t.Run("connect timeout", func(t *testing.T) {
+		// 10.255.255.1 is not routable, so the connection attempt hangs until the connect timeout
+		if conn, err := net.DialTimeout("tcp", "10.255.255.1:80", timeout); err == nil {
+			conn.Close()
+			t.Skip("the network accepts connections to non-routable addresses")
+		}
+		setConfigs(t, ConfigsModel{ConnectTimeout: timeout})
+		client, err := newHTTPClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Now()
+		_, err = client.Get("http://10.255.255.1")
+		if elapsed := time.Since(start); err == nil || elapsed > timeout+2*time.Second {
+			t.Errorf("Get() error = %v after %s, want an error after about %s", err, elapsed, timeout)
+		}
+	})
+
+	t.Run("TLS handshake timeout", func(t *testing.T) {
+		// the listener accepts the connections, but never answers the TLS handshake
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer listener.Close()
+		go func() {
+			var conns []net.Conn
+			defer func() {
+				for _, conn := range conns {
+					conn.Close()
+				}
+			}()
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conns = append(conns, conn)
+			}
+		}()
+		setConfigs(t, ConfigsModel{TLSHandshakeTimeout: timeout})
+		client, err := newHTTPClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Now()
+		_, err = client.Get("https://" + listener.Addr().String())
+		if elapsed := time.Since(start); err == nil || elapsed > timeout+2*time.Second {
+			t.Errorf("Get() error = %v after %s, want an error after about %s", err, elapsed, timeout)
+		}
+	})
+}
+
+func TestNewHTTPClientTransport(t *testing.T) {
+	tests := []struct {
+		name                  string
+		configs               ConfigsModel
+		wantIdleConnTimeout   time.Duration
+		wantDisableKeepAlives bool
+	}{
+		{name: "defaults", wantIdleConnTimeout: defaultIdleConnTimeout},
+		{name: "idle connection timeout", configs: ConfigsModel{IdleConnTimeout: 5 * time.Second}, wantIdleConnTimeout: 5 * time.Second},
+		{name: "keep-alive disabled", configs: ConfigsModel{DisableKeepAlive: true}, wantIdleConnTimeout: defaultIdleConnTimeout, wantDisableKeepAlives: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, tt.configs)
+			client, err := newHTTPClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport, ok := client.Transport.(*http.Transport)
+			if !ok {
+				t.Fatalf("Transport = %T, want *http.Transport", client.Transport)
+			}
+			if transport.IdleConnTimeout != tt.wantIdleConnTimeout {
+				t.Errorf("IdleConnTimeout = %s, want %s", transport.IdleConnTimeout, tt.wantIdleConnTimeout)
+			}
+			if transport.DisableKeepAlives != tt.wantDisableKeepAlives {
+				t.Errorf("DisableKeepAlives = %v, want %v", transport.DisableKeepAlives, tt.wantDisableKeepAlives)
+			}
+		})
+	}
+}
+
+func TestCachingDialerResolveUpFront(t *testing.T) {
+	setConfigs(t, ConfigsModel{RetryCount: 2})
+	var lookups int
+	var dials []string
+	d := newCachingDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		dials = append(dials, address)
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	})
+	d.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		if lookups == 1 {
+			return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	if err := d.resolveUpFront(context.Background(), "rink.hockeyapp.net"); err != nil {
+		t.Fatalf("resolveUpFront() error = %v, want the DNS error retried", err)
+	}
+	conn, err := d.DialContext(context.Background(), "tcp", "rink.hockeyapp.net:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	if lookups != 2 {
+		t.Errorf("%d lookups, want the failed and the successful one only", lookups)
+	}
+	if !reflect.DeepEqual(dials, []string{"10.0.0.1:443"}) {
+		t.Errorf("dialed = %v, want the cached IP", dials)
+	}
+}
+
+func TestCachingDialerParallelLookups(t *testing.T) {
+	release := make(chan struct{})
+	d := newCachingDialer(nil)
+	d.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		if host == "slow.example.com" {
+			<-release
+		}
+		return []string{"10.0.0.1"}, nil
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := d.resolve(context.Background(), "slow.example.com"); err != nil {
+			t.Errorf("resolve() error = %v", err)
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := d.resolve(context.Background(), "fast.example.com"); err != nil {
+			t.Errorf("resolve() error = %v", err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("resolve() waited for the lookup of another host")
+	}
+	close(release)
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultFileContentType = "application/octet-stream"
+
+	gzipExtension = ".gz"
+)
+
+// fileContentTypes maps the (lowercased) file extensions to the Content-Type of the multipart file part,
+// app bundles have no registered media type.
+var fileContentTypes = map[string]string{
+	".apk": "application/vnd.android.package-archive",
+	".aab": defaultFileContentType,
+	".txt": "text/plain",
+	".zip": "application/zip",
+	".gz":  "application/gzip",
+}
+
+func fileContentType(pth string) string {
+	if contentType, ok := fileContentTypes[strings.ToLower(filepath.Ext(pth))]; ok {
+		return contentType
+	}
+	return defaultFileContentType
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// createFormFilePart is like multipart.Writer.CreateFormFile, but sets the Content-Type
+// of the part based on the file extension. Files with .gz extension are sent with gzip Content-Encoding,
+// and the Content-Type of the compressed file. The filename keeps the .gz extension,
+// so a server ignoring the part Content-Encoding stores the file under a name matching its content.
+func createFormFilePart(w *multipart.Writer, fieldName, pth string) (io.Writer, error) {
+	h := make(textproto.MIMEHeader)
+	contentPth := pth
+	if strings.ToLower(filepath.Ext(pth)) == gzipExtension {
+		contentPth = strings.TrimSuffix(pth, filepath.Ext(pth))
+		h.Set("Content-Encoding", "gzip")
+	}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(fieldName), quoteEscaper.Replace(pth)))
+	h.Set("Content-Type", fileContentType(contentPth))
+	return w.CreatePart(h)
+}
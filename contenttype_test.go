@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"testing"
+)
+
+func TestFileContentType(t *testing.T) {
+	tests := []struct {
+		pth  string
+		want string
+	}{
+		{pth: "app-release.apk", want: "application/vnd.android.package-archive"},
+		{pth: "APP-RELEASE.APK", want: "application/vnd.android.package-archive"},
+		{pth: "app-release.aab", want: defaultFileContentType},
+		{pth: "mapping.txt", want: "text/plain"},
+		{pth: "mapping.txt.gz", want: "application/gzip"},
+		{pth: "symbols.zip", want: "application/zip"},
+		{pth: "mapping", want: defaultFileContentType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pth, func(t *testing.T) {
+			if got := fileContentType(tt.pth); got != tt.want {
+				t.Errorf("fileContentType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateFormFilePart(t *testing.T) {
+	tests := []struct {
+		name            string
+		fieldName       string
+		pth             string
+		wantDisposition string
+		wantContentType string
+		wantEncoding    string
+	}{
+		{name: "APK", fieldName: "ipa", pth: "app.apk", wantDisposition: `form-data; name="ipa"; filename="app.apk"`, wantContentType: "application/vnd.android.package-archive"},
+		{name: "quoted filename", fieldName: "dsym", pth: `map"ping\.txt`, wantDisposition: `form-data; name="dsym"; filename="map\"ping\\.txt"`, wantContentType: "text/plain"},
+		{name: "gzipped mapping", fieldName: "dsym", pth: "mapping.txt.gz", wantDisposition: `form-data; name="dsym"; filename="mapping.txt.gz"`, wantContentType: "text/plain", wantEncoding: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			w := multipart.NewWriter(&b)
+			if _, err := createFormFilePart(w, tt.fieldName, tt.pth); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			part, err := multipart.NewReader(&b, w.Boundary()).NextPart()
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if got := part.Header.Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf("Content-Disposition = %s, want %s", got, tt.wantDisposition)
+			}
+			if got := part.Header.Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %s, want %s", got, tt.wantContentType)
+			}
+			if got := part.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %s, want %s", got, tt.wantEncoding)
+			}
+		})
+	}
+}
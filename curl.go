@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	printCurlDisabled = "false"
+	printCurlEnabled  = "true"
+	printCurlOnly     = "only"
+)
+
+// curlTokenPlaceholder is printed instead of the API token, the curl command reads it from the environment.
+const curlTokenPlaceholder = "$HOCKEYAPP_API_TOKEN"
+
+// shellQuote single quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// curlCommand returns a curl command performing the multipart upload request,
+// the secret looking header values are redacted and the API token is read from $HOCKEYAPP_API_TOKEN.
+func curlCommand(method, requestURL string, fields, files map[string]string, headers http.Header) string {
+	args := []string{"curl", "-X " + method}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := headers.Get(name)
+		if isSecretHeader(name) {
+			value = redactedValue
+		}
+		args = append(args, "-H "+shellQuote(fmt.Sprintf("%s: %s", name, value)))
+	}
+	args = append(args, fmt.Sprintf(`-H "X-HockeyAppToken: %s"`, curlTokenPlaceholder))
+
+	for _, key := range sortedKeys(fields) {
+		args = append(args, "--form-string "+shellQuote(key+"="+fields[key]))
+	}
+	for _, key := range sortedKeys(files) {
+		args = append(args, "-F "+shellQuote(key+"=@"+files[key]))
+	}
+	args = append(args, shellQuote(requestURL))
+
+	return strings.Join(args, " \\\n  ")
+}
+
+// printCurl prints the curl command of the upload request, with the secrets redacted.
+func printCurl(method, requestURL string, fields, files map[string]string) {
+	cmd := curlCommand(method, requestURL, fields, files, configs.extraHeaders)
+	printf("Equivalent curl command:\n%s", redact(cmd, []string{configs.APIToken, configs.HMACSecret}))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "notes", want: `'notes'`},
+		{s: "release notes", want: `'release notes'`},
+		{s: "it's", want: `'it'\''s'`},
+		{s: "$HOME", want: `'$HOME'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := shellQuote(tt.s); got != tt.want {
+				t.Errorf("shellQuote() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		files   map[string]string
+		headers http.Header
+		want    string
+	}{
+		{
+			name:   "fields and files",
+			fields: map[string]string{"status": "2", "notes": "it's done"},
+			files:  map[string]string{"ipa": "app.apk"},
+			want: "curl \\\n  -X POST \\\n  -H \"X-HockeyAppToken: $HOCKEYAPP_API_TOKEN\" \\\n" +
+				"  --form-string 'notes=it'\\''s done' \\\n  --form-string 'status=2' \\\n  -F 'ipa=@app.apk' \\\n  'https://rink.hockeyapp.net/api/2/apps/upload'",
+		},
+		{
+			name:    "redacted headers",
+			headers: http.Header{"X-Trace": {"abc"}, "Authorization": {"Bearer secret"}},
+			want: "curl \\\n  -X POST \\\n  -H 'Authorization: " + redactedValue + "' \\\n  -H 'X-Trace: abc' \\\n" +
+				"  -H \"X-HockeyAppToken: $HOCKEYAPP_API_TOKEN\" \\\n  'https://rink.hockeyapp.net/api/2/apps/upload'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := curlCommand("POST", "https://rink.hockeyapp.net/api/2/apps/upload", tt.fields, tt.files, tt.headers)
+			if got != tt.want {
+				t.Errorf("curlCommand() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// diskSpaceMargin is the free space required on top of the largest upload.
+const diskSpaceMargin = 10 * 1024 * 1024
+
+func fileSize(pth string) (uint64, error) {
+	info, err := os.Stat(pth)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(info.Size()), nil
+}
+
+// requiredDiskSpace returns the free space needed to prepare the largest upload
+// (the artifact and the mapping file).
+func requiredDiskSpace(artifacts []ArtifactModel, mappingPath string) (uint64, error) {
+	var mappingSize uint64
+	if mappingPath != "" {
+		size, err := fileSize(mappingPath)
+		if err != nil {
+			return 0, err
+		}
+		mappingSize = size
+	}
+
+	var largest uint64
+	for _, artifact := range artifacts {
+		size, err := fileSize(artifact.Path)
+		if err != nil {
+			return 0, err
+		}
+		if size > largest {
+			largest = size
+		}
+	}
+
+	return largest + mappingSize + diskSpaceMargin, nil
+}
+
+// checkFreeDiskSpace returns an error if dir has less free space than required.
+func checkFreeDiskSpace(dir string, required uint64) error {
+	free, ok, err := freeDiskSpace(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check free disk space at: %s, error: %v", dir, err)
+	}
+	if !ok {
+		return nil
+	}
+	if free < required {
+		return fmt.Errorf("not enough free disk space at: %s, required: %d bytes, available: %d bytes", dir, required, free)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestRequiredDiskSpace(t *testing.T) {
+	apkSize, err := fileSize("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alignedSize, err := fileSize("testdata/aligned.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mappingSize, err := fileSize("testdata/output-metadata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		artifacts   []ArtifactModel
+		mappingPath string
+		want        uint64
+		wantErr     bool
+	}{
+		{name: "largest artifact", artifacts: []ArtifactModel{{Path: "testdata/app.apk"}, {Path: "testdata/aligned.apk"}}, want: alignedSize + diskSpaceMargin},
+		{name: "with mapping", artifacts: []ArtifactModel{{Path: "testdata/app.apk"}}, mappingPath: "testdata/output-metadata.json", want: apkSize + mappingSize + diskSpaceMargin},
+		{name: "missing artifact", artifacts: []ArtifactModel{{Path: "testdata/missing.apk"}}, wantErr: true},
+		{name: "missing mapping", artifacts: []ArtifactModel{{Path: "testdata/app.apk"}}, mappingPath: "testdata/missing.txt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := requiredDiskSpace(tt.artifacts, tt.mappingPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requiredDiskSpace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("requiredDiskSpace() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckFreeDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	_, supported, err := freeDiskSpace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		dir      string
+		required uint64
+		wantErr  bool
+	}{
+		{name: "enough space", dir: dir, required: 1},
+		{name: "not enough space", dir: dir, required: math.MaxUint64, wantErr: supported},
+		{name: "missing dir", dir: filepath.Join(dir, "missing"), required: 1, wantErr: supported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkFreeDiskSpace(tt.dir, tt.required); (err != nil) != tt.wantErr {
+				t.Errorf("checkFreeDiskSpace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
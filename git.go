@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/command"
+)
+
+// gitCommitSHA returns the commit hash of HEAD in the git repository of dir,
+// the current directory is used if dir is empty.
+func gitCommitSHA(dir string) (string, error) {
+	cmd := command.New("git", "rev-parse", "HEAD").SetDir(dir)
+	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s failed, output: %s, error: %v", cmd.PrintableCommandArgs(), out, err)
+	}
+	return out, nil
+}
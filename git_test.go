@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestGitCommitSHA(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed, output: %s, error: %v", args, out, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr bool
+	}{
+		{name: "repository", dir: repo},
+		{name: "not a repository", dir: t.TempDir(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gitCommitSHA(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gitCommitSHA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(got) != 40 {
+				t.Errorf("gitCommitSHA() = %q, want a commit SHA", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// protectedHeaders can not be set by the extra_headers input:
+// the auth headers, the multipart Content-Type (with the boundary), the idempotency key and the HMAC timestamp.
+// The HMACHeader is protected too if HMACSecret is set.
+var protectedHeaders = []string{"X-HockeyAppToken", "X-API-Token", "Content-Type", "Idempotency-Key", hmacTimestampHeader}
+
+func isProtectedHeader(name string) bool {
+	for _, protected := range protectedHeaders {
+		if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(protected) {
+			return true
+		}
+	}
+	return configs.HMACSecret != "" && http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(configs.HMACHeader)
+}
+
+// secretHeaderSignatures are the (lowercased) header name parts whose values are redacted in the logs.
+var secretHeaderSignatures = []string{"authorization", "token", "secret", "password", "key", "cookie"}
+
+func isValidHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseExtraHeaders parses a JSON object or newline separated `Name: value` pairs.
+func parseExtraHeaders(s string) (http.Header, error) {
+	headers := http.Header{}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return headers, nil
+	}
+
+	pairs := map[string]string{}
+	if strings.HasPrefix(s, "{") {
+		if err := json.Unmarshal([]byte(s), &pairs); err != nil {
+			return nil, fmt.Errorf("invalid ExtraHeaders JSON, error: %v", err)
+		}
+	} else {
+		for _, line := range strings.Split(s, "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			split := strings.SplitN(line, ":", 2)
+			if len(split) != 2 {
+				return nil, fmt.Errorf("invalid ExtraHeaders line: %s, it should be in `Name: value` format", line)
+			}
+			pairs[split[0]] = split[1]
+		}
+	}
+
+	for name, value := range pairs {
+		name = strings.TrimSpace(name)
+		if !isValidHeaderName(name) {
+			return nil, fmt.Errorf("invalid ExtraHeaders header name: %q", name)
+		}
+		if isProtectedHeader(name) {
+			return nil, fmt.Errorf("invalid ExtraHeaders: %s header can not be overridden", http.CanonicalHeaderKey(name))
+		}
+		headers.Set(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, signature := range secretHeaderSignatures {
+		if strings.Contains(name, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// printableHeaders returns the headers sorted by name, with the secret looking values redacted.
+func printableHeaders(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var pairs []string
+	for _, name := range names {
+		value := headers.Get(name)
+		if isSecretHeader(name) {
+			value = redactedValue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s: %s", name, value))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// setExtraHeaders sets the extra_headers input headers on the request,
+// it should be called before setting the protected headers.
+func setExtraHeaders(request *http.Request) {
+	for name, values := range configs.extraHeaders {
+		request.Header[name] = values
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseExtraHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		hmacSecret string
+		want       http.Header
+		wantErr    bool
+	}{
+		{name: "empty", s: " ", want: http.Header{}},
+		{name: "lines", s: "X-Trace: abc\n\n x-team : mobile \n", want: http.Header{"X-Trace": {"abc"}, "X-Team": {"mobile"}}},
+		{name: "JSON", s: `{"X-Trace": "abc", "Authorization": "Bearer token"}`, want: http.Header{"X-Trace": {"abc"}, "Authorization": {"Bearer token"}}},
+		{name: "value with colon", s: "X-Url: https://example.com", want: http.Header{"X-Url": {"https://example.com"}}},
+		{name: "invalid line", s: "X-Trace", wantErr: true},
+		{name: "invalid JSON", s: `{"X-Trace": 1}`, wantErr: true},
+		{name: "invalid name", s: "X Trace: abc", wantErr: true},
+		{name: "token header", s: "x-hockeyapptoken: token", wantErr: true},
+		{name: "Content-Type header", s: "Content-Type: application/json", wantErr: true},
+		{name: "Idempotency-Key header", s: "Idempotency-Key: key", wantErr: true},
+		{name: "HMAC timestamp header", s: hmacTimestampHeader + ": 1", wantErr: true},
+		{name: "HMAC header without secret", s: "X-Signature: abc", want: http.Header{"X-Signature": {"abc"}}},
+		{name: "HMAC header with secret", s: "X-Signature: abc", hmacSecret: "secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{HMACSecret: tt.hmacSecret, HMACHeader: "X-Signature"})
+			got, err := parseExtraHeaders(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExtraHeaders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseExtraHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintableHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers http.Header
+		want    string
+	}{
+		{name: "no headers", headers: http.Header{}, want: ""},
+		{name: "sorted", headers: http.Header{"X-Trace": {"abc"}, "Accept": {"application/json"}}, want: "Accept: application/json, X-Trace: abc"},
+		{name: "redacted", headers: http.Header{"Authorization": {"Bearer token"}, "X-Api-Key": {"key"}, "Cookie": {"session"}}, want: "Authorization: [REDACTED], Cookie: [REDACTED], X-Api-Key: [REDACTED]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := printableHeaders(tt.headers); got != tt.want {
+				t.Errorf("printableHeaders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
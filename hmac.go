@@ -0,0 +1,29 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const hmacTimestampHeader = "X-Signature-Timestamp"
+
+// requestSignature returns the hex encoded HMAC-SHA256 of the newline separated method, path and timestamp.
+func requestSignature(secret, method, path, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest sets the HMAC signature and the timestamp headers on the request if HMACSecret is set.
+func signRequest(request *http.Request, now time.Time) {
+	if configs.HMACSecret == "" {
+		return
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	request.Header.Set(hmacTimestampHeader, timestamp)
+	request.Header.Set(configs.HMACHeader, requestSignature(configs.HMACSecret, request.Method, request.URL.EscapedPath(), timestamp))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSignRequest(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		secret        string
+		wantSignature string
+	}{
+		{name: "not signed without secret", method: "POST", url: "https://rink.hockeyapp.net/api/2/apps/upload"},
+		{name: "signed", method: "POST", url: "https://rink.hockeyapp.net/api/2/apps/upload?query=ignored", secret: "secret", wantSignature: "d2e3769b934c10bd3a37c675f5e11b3fcb8ef03203e48fc818202008c62d8251"},
+		{name: "escaped path", method: "GET", url: "https://rink.hockeyapp.net/api/2/apps/a%20b/app_versions", secret: "secret", wantSignature: "aa29db6461f4265ad542c45b577a5bbfb850166a07199dbd7231bd57ca5705eb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{HMACSecret: tt.secret, HMACHeader: "X-Signature"})
+			request, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signRequest(request, now)
+
+			if got := request.Header.Get("X-Signature"); got != tt.wantSignature {
+				t.Errorf("signature = %q, want %q", got, tt.wantSignature)
+			}
+			wantTimestamp := ""
+			if tt.secret != "" {
+				wantTimestamp = "1700000000"
+			}
+			if got := request.Header.Get(hmacTimestampHeader); got != wantTimestamp {
+				t.Errorf("timestamp = %q, want %q", got, wantTimestamp)
+			}
+		})
+	}
+}
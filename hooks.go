@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bitrise-io/go-utils/command"
+)
+
+const (
+	hookArtifactPathKey = "HOCKEYAPP_ARTIFACT_PATH"
+	hookArtifactTypeKey = "HOCKEYAPP_ARTIFACT_TYPE"
+)
+
+func preUploadHookEnvs(artifact ArtifactModel) []string {
+	return []string{
+		fmt.Sprintf("%s=%s", hookArtifactPathKey, artifact.Path),
+		fmt.Sprintf("%s=%s", hookArtifactTypeKey, artifact.Type),
+	}
+}
+
+func postUploadHookEnvs(artifact ArtifactModel, response ResponseModel) []string {
+	return append(preUploadHookEnvs(artifact),
+		fmt.Sprintf("%s=%s", hockeyAppDeployPublicURLKey, response.PublicURL),
+		fmt.Sprintf("%s=%s", hockeyAppDeployBuildURLKey, response.BuildURL),
+		fmt.Sprintf("%s=%s", hockeyAppDeployConfigURLKey, response.ConfigURL),
+	)
+}
+
+// runHook runs the command string with sh, the envs are appended to the step's environment.
+func runHook(name, cmdStr string, envs []string) error {
+	printNewline()
+	infof("Running %s command", name)
+
+	cmd := command.New("sh", "-c", cmdStr).AppendEnvs(envs...)
+	printf("$ %s", cmd.PrintableCommandArgs())
+
+	out, err := cmd.RunAndReturnTrimmedCombinedOutput()
+	if out != "" {
+		printf("%s", out)
+	}
+	if err != nil {
+		return fmt.Errorf("%s command failed, error: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPostUploadHookEnvs(t *testing.T) {
+	artifact := ArtifactModel{Type: artifactTypeAPK, Path: "app.apk"}
+	response := ResponseModel{PublicURL: "https://install", BuildURL: "https://download", ConfigURL: "https://config"}
+	want := []string{
+		hookArtifactPathKey + "=app.apk",
+		hookArtifactTypeKey + "=apk",
+		hockeyAppDeployPublicURLKey + "=https://install",
+		hockeyAppDeployBuildURLKey + "=https://download",
+		hockeyAppDeployConfigURLKey + "=https://config",
+	}
+	if got := postUploadHookEnvs(artifact, response); !reflect.DeepEqual(got, want) {
+		t.Errorf("postUploadHookEnvs() = %v, want %v", got, want)
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		cmdStr   string
+		envs     []string
+		wantErr  bool
+		wantFile string
+	}{
+		{name: "envs passed", cmdStr: `printf "%s" "$` + hookArtifactPathKey + `" > ` + filepath.Join(dir, "out"), envs: preUploadHookEnvs(ArtifactModel{Type: artifactTypeAPK, Path: "app.apk"}), wantFile: "app.apk"},
+		{name: "failing command", cmdStr: "exit 3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := runHook("pre_upload", tt.cmdStr, tt.envs); (err != nil) != tt.wantErr {
+				t.Fatalf("runHook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantFile == "" {
+				return
+			}
+			if out, err := ioutil.ReadFile(filepath.Join(dir, "out")); err != nil || string(out) != tt.wantFile {
+				t.Errorf("hook output = %q (error: %v), want %q", out, err, tt.wantFile)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	installHTMLPublicLinkTemplate = `<a href="{public_url}">Install {version}</a>`
+	installHTMLBuildLinkTemplate  = `<a href="{build_url}">Download {version}</a>`
+)
+
+// defaultInstallHTMLTemplate links to the available URLs.
+func defaultInstallHTMLTemplate(publicURL, buildURL string) string {
+	var links []string
+	if publicURL != "" {
+		links = append(links, installHTMLPublicLinkTemplate)
+	}
+	if buildURL != "" {
+		links = append(links, installHTMLBuildLinkTemplate)
+	}
+	return strings.Join(links, "\n")
+}
+
+// renderInstallHTML substitutes the HTML escaped values into the template:
+// {public_url}, {build_url} and {version} are replaced.
+func renderInstallHTML(template, version, publicURL, buildURL string) string {
+	if template == "" {
+		template = defaultInstallHTMLTemplate(publicURL, buildURL)
+	}
+	return strings.NewReplacer(
+		"{public_url}", html.EscapeString(publicURL),
+		"{build_url}", html.EscapeString(buildURL),
+		"{version}", html.EscapeString(version),
+	).Replace(template)
+}
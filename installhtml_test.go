@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestRenderInstallHTML(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		version   string
+		publicURL string
+		buildURL  string
+		want      string
+	}{
+		{
+			name:      "default template",
+			version:   "1.2.3 (42)",
+			publicURL: "https://install",
+			buildURL:  "https://download",
+			want:      `<a href="https://install">Install 1.2.3 (42)</a>` + "\n" + `<a href="https://download">Download 1.2.3 (42)</a>`,
+		},
+		{
+			name:      "default template without build URL",
+			version:   "1.2.3",
+			publicURL: "https://install",
+			want:      `<a href="https://install">Install 1.2.3</a>`,
+		},
+		{
+			name:      "custom template",
+			template:  `<p>{version}</p><a href="{public_url}">{public_url}</a>`,
+			version:   "1.2.3",
+			publicURL: "https://install",
+			want:      `<p>1.2.3</p><a href="https://install">https://install</a>`,
+		},
+		{
+			name:      "escaped values",
+			template:  `<a href="{public_url}">{version}</a>`,
+			version:   `<script>"1"</script>`,
+			publicURL: `https://install?a=1&b="2"`,
+			want:      `<a href="https://install?a=1&amp;b=&#34;2&#34;">&lt;script&gt;&#34;1&#34;&lt;/script&gt;</a>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderInstallHTML(tt.template, tt.version, tt.publicURL, tt.buildURL); got != tt.want {
+				t.Errorf("renderInstallHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
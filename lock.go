@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const lockPollInterval = 500 * time.Millisecond
+
+// fileLock is an exclusive lock held on a file, serializing the concurrent deploys on the same machine.
+type fileLock struct {
+	file *os.File
+}
+
+// acquireFileLock blocks until the exclusive lock of the file at pth is acquired or the timeout elapses.
+func acquireFileLock(pth string, timeout time.Duration) (*fileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(pth, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			return nil, closeWithError(f, err)
+		}
+		if locked {
+			return &fileLock{file: f}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, closeWithError(f, fmt.Errorf("timed out after %s waiting for the lock: %s", timeout, pth))
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Release ...
+func (l *fileLock) Release() error {
+	if err := unlockFile(l.file); err != nil {
+		return closeWithError(l.file, err)
+	}
+	return l.file.Close()
+}
+
+func closeWithError(f *os.File, err error) error {
+	if cerr := f.Close(); cerr != nil {
+		return fmt.Errorf("%v, and failed to close file: %v", err, cerr)
+	}
+	return err
+}
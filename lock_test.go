@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquireFileLock(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "locks", "deploy.lock")
+
+	lock, err := acquireFileLock(pth, 0)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+	if _, err := acquireFileLock(pth, 0); err == nil {
+		t.Fatal("acquireFileLock() of a held lock succeeded, want a timeout error")
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	lock, err = acquireFileLock(pth, 0)
+	if err != nil {
+		t.Fatalf("acquireFileLock() of a released lock error = %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+)
+
+// tryLockFile is not supported on windows, the deploys are not serialized.
+func tryLockFile(f *os.File) (bool, error) {
+	warnf("File locking is not supported on windows, %s is not locked", f.Name())
+	return true, nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// ansiStrippingWriter removes the ANSI color codes before writing to the underlying writer.
+type ansiStrippingWriter struct {
+	writer io.Writer
+}
+
+func (w ansiStrippingWriter) Write(p []byte) (int, error) {
+	if _, err := w.writer.Write(ansiEscapeRegexp.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// teeLogToFile makes the log helpers write to the file at pth too (without color codes),
+// the returned function closes the file.
+func teeLogToFile(pth string) (func() error, error) {
+	if err := os.MkdirAll(filepath.Dir(pth), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(pth, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	setLogOutput(io.MultiWriter(os.Stdout, ansiStrippingWriter{writer: f}))
+	return func() error {
+		setLogOutput(os.Stdout)
+		return f.Close()
+	}, nil
+}
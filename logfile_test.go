@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnsiStrippingWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain", s: "Uploading app.apk", want: "Uploading app.apk"},
+		{name: "colored", s: "\x1b[33;1mAttempt 1/4 failed\x1b[0m", want: "Attempt 1/4 failed"},
+		{name: "reset only", s: "done\x1b[0m\n", want: "done\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			n, err := ansiStrippingWriter{writer: &b}.Write([]byte(tt.s))
+			if err != nil || n != len(tt.s) {
+				t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(tt.s))
+			}
+			if b.String() != tt.want {
+				t.Errorf("written = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTeeLogToFile(t *testing.T) {
+	setConfigs(t, ConfigsModel{})
+	pth := filepath.Join(t.TempDir(), "logs", "deploy.log")
+
+	closeLog, err := teeLogToFile(pth)
+	if err != nil {
+		t.Fatalf("teeLogToFile() error = %v", err)
+	}
+	noticef("logged to the file")
+	if err := closeLog(); err != nil {
+		t.Fatalf("closing the log file error = %v", err)
+	}
+	printf("not logged to the file")
+
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(content); !strings.Contains(got, "logged to the file") || strings.Contains(got, "not logged") || strings.Contains(got, "\x1b[") {
+		t.Errorf("log file = %q, want the message logged before closing, without color codes", got)
+	}
+}
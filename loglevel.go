@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bitrise-io/go-utils/log"
+)
+
+const (
+	logLevelError = "error"
+	logLevelWarn  = "warn"
+	logLevelInfo  = "info"
+	logLevelDebug = "debug"
+)
+
+// logLevels maps the log levels to their verbosity, a message is printed if its level is at most the configured one.
+var logLevels = map[string]int{
+	logLevelError: 0,
+	logLevelWarn:  1,
+	logLevelInfo:  2,
+	logLevelDebug: 3,
+}
+
+// logEnabled reports whether the messages of the level are printed with the configured LogLevel,
+// an empty or unknown LogLevel is handled as info.
+func logEnabled(level string) bool {
+	configured, ok := logLevels[configs.LogLevel]
+	if !ok {
+		configured = logLevels[logLevelInfo]
+	}
+	return logLevels[level] <= configured
+}
+
+// The log helpers print the message only if the LogLevel allows it, the errors are always printed.
+
+func debugf(format string, v ...interface{}) {
+	if logEnabled(logLevelDebug) {
+		log.Debugf(format, v...)
+	}
+}
+
+func infof(format string, v ...interface{}) {
+	if logEnabled(logLevelInfo) {
+		log.Infof(format, v...)
+	}
+}
+
+func printf(format string, v ...interface{}) {
+	if logEnabled(logLevelInfo) {
+		log.Printf(format, v...)
+	}
+}
+
+func donef(format string, v ...interface{}) {
+	if logEnabled(logLevelInfo) {
+		log.Donef(format, v...)
+	}
+}
+
+// noticef prints a warning level message, which is not recorded as a warning for WarningsAsErrors.
+func noticef(format string, v ...interface{}) {
+	if logEnabled(logLevelWarn) {
+		log.Warnf(format, v...)
+	}
+}
+
+// printNewline prints an empty line to separate the sections of the log.
+func printNewline() {
+	if logEnabled(logLevelInfo) {
+		fmt.Println()
+	}
+}
+
+// setLogOutput makes the log helpers write to the writer, with the matches of the RedactPatterns replaced.
+func setLogOutput(writer io.Writer) {
+	if len(configs.redactPatterns) > 0 {
+		writer = redactingWriter{writer: writer, patterns: configs.redactPatterns}
+	}
+	log.SetOutWriter(writer)
+	log.SetEnableDebugLog(logEnabled(logLevelDebug))
+}
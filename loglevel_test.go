@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// captureLog returns the log output of f, printed with the current configs.
+func captureLog(t *testing.T, f func()) string {
+	t.Helper()
+	var b bytes.Buffer
+	setLogOutput(&b)
+	defer setLogOutput(os.Stdout)
+	f()
+	return b.String()
+}
+
+func TestLogEnabled(t *testing.T) {
+	tests := []struct {
+		logLevel string
+		want     map[string]bool
+	}{
+		{logLevel: "", want: map[string]bool{logLevelError: true, logLevelWarn: true, logLevelInfo: true, logLevelDebug: false}},
+		{logLevel: "verbose", want: map[string]bool{logLevelError: true, logLevelWarn: true, logLevelInfo: true, logLevelDebug: false}},
+		{logLevel: logLevelError, want: map[string]bool{logLevelError: true, logLevelWarn: false, logLevelInfo: false, logLevelDebug: false}},
+		{logLevel: logLevelWarn, want: map[string]bool{logLevelError: true, logLevelWarn: true, logLevelInfo: false, logLevelDebug: false}},
+		{logLevel: logLevelDebug, want: map[string]bool{logLevelError: true, logLevelWarn: true, logLevelInfo: true, logLevelDebug: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.logLevel, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{LogLevel: tt.logLevel})
+			got := map[string]bool{}
+			for level := range logLevels {
+				got[level] = logEnabled(level)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogHelpers(t *testing.T) {
+	tests := []struct {
+		logLevel string
+		want     []string
+	}{
+		{logLevel: logLevelDebug, want: []string{"debug message", "info message", "print message", "done message", "notice message"}},
+		{logLevel: logLevelInfo, want: []string{"info message", "print message", "done message", "notice message"}},
+		{logLevel: logLevelWarn, want: []string{"notice message"}},
+		{logLevel: logLevelError, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.logLevel, func(t *testing.T) {
+			// The cleanups run in reverse order: the log output is reset after the configs are restored.
+			t.Cleanup(func() { setLogOutput(os.Stdout) })
+			setConfigs(t, ConfigsModel{LogLevel: tt.logLevel})
+			var b bytes.Buffer
+			setLogOutput(&b)
+
+			debugf("debug message")
+			infof("info message")
+			printf("print message")
+			donef("done message")
+			noticef("notice message")
+
+			var got []string
+			for _, message := range []string{"debug message", "info message", "print message", "done message", "notice message"} {
+				if strings.Contains(b.String(), message) {
+					got = append(got, message)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("printed messages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,14 +13,25 @@ import (
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptrace"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bitrise-io/depman/pathutil"
-	"github.com/bitrise-io/go-utils/command"
 	"github.com/bitrise-io/go-utils/log"
 )
 
+// hockeyAppAPIURL is a variable, so the tests can point it at a test server.
+var hockeyAppAPIURL = "https://rink.hockeyapp.net/api/2"
+
 const (
 	hockeyAppDeployStatusKey     = "HOCKEYAPP_DEPLOY_STATUS"
 	hockeyAppDeployStatusSuccess = "success"
@@ -29,6 +44,30 @@ const (
 	hockeyAppDeployPublicURLKeyList = "HOCKEYAPP_DEPLOY_PUBLIC_URL_LIST"
 	hockeyAppDeployBuildURLKeyList  = "HOCKEYAPP_DEPLOY_BUILD_URL_LIST"
 	hockeyAppDeployConfigURLKeyList = "HOCKEYAPP_DEPLOY_CONFIG_URL_LIST"
+
+	hockeyAppDeployUploadSpeedKey = "HOCKEYAPP_DEPLOY_UPLOAD_SPEED_MBPS"
+	hockeyAppDeployUploadSizeKey  = "HOCKEYAPP_DEPLOY_UPLOAD_SIZE_BYTES"
+
+	hockeyAppDeployVersionCodeKey = "HOCKEYAPP_DEPLOY_VERSION_CODE"
+	hockeyAppDeployVersionNameKey = "HOCKEYAPP_DEPLOY_VERSION_NAME"
+
+	hockeyAppDeployAttemptsKey = "HOCKEYAPP_DEPLOY_ATTEMPTS"
+
+	hockeyAppDeployDeepLinkKey = "HOCKEYAPP_DEPLOY_DEEP_LINK"
+
+	hockeyAppDeployPartialKey   = "HOCKEYAPP_DEPLOY_PARTIAL"
+	hockeyAppDeployStatusMapKey = "HOCKEYAPP_DEPLOY_STATUS_MAP"
+	hockeyAppDeployChecksumKey  = "HOCKEYAPP_DEPLOY_ARTIFACT_SHA256"
+
+	hockeyAppDeployProcessingKey = "HOCKEYAPP_DEPLOY_PROCESSING_STATE"
+
+	hockeyAppDeployShortURLKey = "HOCKEYAPP_DEPLOY_SHORT_URL"
+
+	hockeyAppDeployRequestURLKey = "HOCKEYAPP_DEPLOY_REQUEST_URL"
+
+	hockeyAppDeployInstallHTMLKey = "HOCKEYAPP_DEPLOY_INSTALL_HTML"
+
+	hockeyAppDeployLocationURLKey = "HOCKEYAPP_DEPLOY_LOCATION_URL"
 )
 
 var configs ConfigsModel
@@ -36,6 +75,7 @@ var configs ConfigsModel
 // ConfigsModel ...
 type ConfigsModel struct {
 	ApkPath        []string
+	AabPath        []string
 	MappingPath    string
 	APIToken       string
 	AppID          string
@@ -45,12 +85,306 @@ type ConfigsModel struct {
 	Status         string
 	Tags           string
 	CommitSHA      string
+	AutoCommitSHA  bool
 	BuildServerURL string
 	RepositoryURL  string
 	Mandatory      string
+	IdempotencyKey string
+	OutputFormat   string
+	DotenvPath     string
+	StrictMode     bool
+
+	DeployBranchFilter []string
+	CurrentBranch      string
+
+	RetryCount int
+	RetryWait  time.Duration
+	CacheDNS   bool
+
+	AutoTagBuildNumber bool
+	BuildNumberEnv     string
+
+	PrintSummary bool
+
+	CACertPath     string
+	UnixSocketPath string
+
+	RequireMapping  bool
+	CompressMapping bool
+
+	TargetVersion      string
+	TargetShortVersion string
+
+	TotalTimeout time.Duration
+
+	LogFilePath string
+
+	AllowEmptyResponse bool
+
+	BuildIdentifier string
+
+	AppIDPath string
+	AppIDKey  string
+
+	BuildTimestamp string
+	buildTime      time.Time
+
+	PreUploadCommand  string
+	PostUploadCommand string
+
+	LockFilePath string
+	LockTimeout  time.Duration
+
+	ExpectedPackageName     string
+	VerifySigningCertSHA256 string
+	ReadManifest            bool
+
+	JSONStatusToStderr bool
+
+	ExportFields []string
+
+	LogConfig bool
+
+	APIFlavor string
+
+	ConnectTimeout      time.Duration
+	TLSHandshakeTimeout time.Duration
+
+	DeepLinkTemplate string
+
+	PartialFailureMode string
+
+	WorkingDir string
+
+	ExtraHeaders string
+	extraHeaders http.Header
+
+	OutputKeyPrefix string
+
+	Metadata string
+
+	EmitStepSummary bool
+
+	ExpectContinue bool
+
+	HMACSecret string
+	HMACHeader string
+
+	VerifyServerChecksum bool
+
+	ApkPathCandidates []string
+
+	WarmupConnection bool
+
+	TraceOutputPath string
+
+	MetricsPushgatewayURL string
+	MetricsLabels         string
+
+	MinSDKRequired  int
+	MinSDKCheckMode string
+
+	WarningsAsErrors bool
+
+	ParallelUploads bool
+
+	RequireZipalign bool
+
+	CredentialsFile string
+
+	WaitForProcessing bool
+	ProcessingTimeout time.Duration
+
+	AutoDetectNotesType bool
+
+	PrintCurl string
+
+	RetryOnBodyRegex string
+	retryBodyRegexp  *regexp.Regexp
+
+	ShortenerURL string
+
+	DistributionGroupNames []string
+	teamIDs                []string
+
+	PackageToPath     string
+	UploadFromPackage string
+
+	SlackWebhookURL             string
+	SlackMessageTemplate        string
+	SlackSuccessMessageTemplate string
+	SlackFailureMessageTemplate string
+
+	NetworkConfigJSON string
+	ProxyURL          string
+	NoProxy           []string
+	TLSMinVersion     string
+
+	UploadAction string
+
+	LogLevel string
+
+	OutputMetadataPath      string
+	OutputMetadataSelection string
+	outputMetadataManifest  *ManifestModel
+
+	RequireDistributable bool
+
+	ValidationConcurrency int
+
+	RedactPatterns string
+	redactPatterns []*regexp.Regexp
+
+	AllowedHosts []string
+
+	TempDir       string
+	CreateTempDir bool
+
+	IdempotentRetry bool
+
+	ExportInstallHTML   bool
+	InstallHTMLTemplate string
+	InstallHTMLPath     string
+
+	ApkURL string
+
+	LocalizedNotes string
+
+	ForbidDebuggable bool
+
+	NotesOverflowMode string
+	NotesMaxLength    int
+
+	WriteUploadManifest bool
+	UploadManifestPath  string
+
+	RequirePublicURL bool
+
+	ExtraQueryParams string
+	extraQueryParams url.Values
+
+	MaxResponseBytes int64
+
+	EmitAnnotation bool
+
+	PostVerify bool
+
+	RetryMaxWait time.Duration
+
+	NotesOnly bool
+
+	IdleConnTimeout  time.Duration
+	DisableKeepAlive bool
+}
+
+func splitPipeSeparatedList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, "|") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func splitNewlineSeparatedList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, "\n") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func splitCommaSeparatedList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseOptionalSeconds parses the number of seconds in the env var,
+// returns 0 if it is empty and -1 if it is invalid.
+func parseOptionalSeconds(key string) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil {
+		return -1
+	}
+	return time.Duration(seconds) * time.Second
 }
 
+// The defaults of the retry and lock inputs (as in step.yml), used if the inputs are not set,
+// for example if the step runs outside of Bitrise.
+const (
+	defaultRetryCount         = 3
+	defaultRetryWaitSeconds   = 5
+	defaultLockTimeoutSeconds = 300
+)
+
 func createConfigsModelFromEnvs() ConfigsModel {
+	var err error
+	retryCount := defaultRetryCount
+	if count := os.Getenv("retry_count"); count != "" {
+		if retryCount, err = strconv.Atoi(count); err != nil {
+			retryCount = -1
+		}
+	}
+	retryWaitSeconds := defaultRetryWaitSeconds
+	if wait := os.Getenv("retry_wait_seconds"); wait != "" {
+		if retryWaitSeconds, err = strconv.Atoi(wait); err != nil {
+			retryWaitSeconds = -1
+		}
+	}
+	lockTimeoutSeconds := defaultLockTimeoutSeconds
+	if timeout := os.Getenv("lock_timeout_seconds"); timeout != "" {
+		if lockTimeoutSeconds, err = strconv.Atoi(timeout); err != nil {
+			lockTimeoutSeconds = -1
+		}
+	}
+	totalTimeoutSeconds := 0
+	if totalTimeout := os.Getenv("total_timeout"); totalTimeout != "" {
+		if totalTimeoutSeconds, err = strconv.Atoi(totalTimeout); err != nil {
+			totalTimeoutSeconds = -1
+		}
+	}
+	minSDKRequired := 0
+	if minSDK := os.Getenv("min_sdk_required"); minSDK != "" {
+		if minSDKRequired, err = strconv.Atoi(minSDK); err != nil {
+			minSDKRequired = -1
+		}
+	}
+	validationConcurrency := 0
+	if concurrency := os.Getenv("validation_concurrency"); concurrency != "" {
+		if validationConcurrency, err = strconv.Atoi(concurrency); err != nil {
+			validationConcurrency = -1
+		}
+	}
+	maxResponseBytes := int64(defaultMaxResponseBytes)
+	if maxBytes := os.Getenv("max_response_bytes"); maxBytes != "" {
+		if maxResponseBytes, err = strconv.ParseInt(maxBytes, 10, 64); err != nil {
+			maxResponseBytes = -1
+		}
+	}
+	retryMaxWaitSeconds := 0
+	if maxWait := os.Getenv("retry_max_wait_seconds"); maxWait != "" {
+		if retryMaxWaitSeconds, err = strconv.Atoi(maxWait); err != nil {
+			retryMaxWaitSeconds = -1
+		}
+	}
+	notesMaxLength := 0
+	if maxLength := os.Getenv("notes_max_length"); maxLength != "" {
+		if notesMaxLength, err = strconv.Atoi(maxLength); err != nil {
+			notesMaxLength = -1
+		}
+	}
 
 	mandatory := os.Getenv("mandatory")
 	if mandatory == "1" || mandatory == "true" {
@@ -59,15 +393,9 @@ func createConfigsModelFromEnvs() ConfigsModel {
 		mandatory = "0"
 	}
 
-	apkPath := []string{}
-	for _, pth := range strings.Split(os.Getenv("apk_path"), "|") {
-		if pth != "" {
-			apkPath = append(apkPath, pth)
-		}
-	}
-
 	return ConfigsModel{
-		ApkPath:        apkPath,
+		ApkPath:        splitPipeSeparatedList(os.Getenv("apk_path")),
+		AabPath:        splitPipeSeparatedList(os.Getenv("aab_path")),
 		MappingPath:    os.Getenv("mapping_path"),
 		APIToken:       os.Getenv("api_token"),
 		AppID:          os.Getenv("app_id"),
@@ -77,40 +405,418 @@ func createConfigsModelFromEnvs() ConfigsModel {
 		Status:         os.Getenv("status"),
 		Tags:           os.Getenv("tags"),
 		CommitSHA:      os.Getenv("commit_sha"),
+		AutoCommitSHA:  os.Getenv("auto_commit_sha") == "true",
 		BuildServerURL: os.Getenv("build_server_url"),
 		RepositoryURL:  os.Getenv("repository_url"),
 		Mandatory:      mandatory,
+		IdempotencyKey: os.Getenv("idempotency_key"),
+		OutputFormat:   os.Getenv("output_format"),
+		DotenvPath:     os.Getenv("dotenv_path"),
+		StrictMode:     os.Getenv("strict_mode") == "true",
+
+		DeployBranchFilter: splitCommaSeparatedList(os.Getenv("deploy_branch_filter")),
+		CurrentBranch:      os.Getenv("current_branch"),
+
+		RetryCount: retryCount,
+		RetryWait:  time.Duration(retryWaitSeconds) * time.Second,
+		CacheDNS:   os.Getenv("cache_dns") == "true",
+
+		AutoTagBuildNumber: os.Getenv("auto_tag_build_number") == "true",
+		BuildNumberEnv:     os.Getenv("build_number_env"),
+
+		PrintSummary: os.Getenv("print_summary") != "false",
+
+		CACertPath:     os.Getenv("ca_cert_path"),
+		UnixSocketPath: os.Getenv("unix_socket_path"),
+
+		RequireMapping:  os.Getenv("require_mapping") == "true",
+		CompressMapping: os.Getenv("compress_mapping") == "true",
+
+		TargetVersion:      os.Getenv("target_version"),
+		TargetShortVersion: os.Getenv("target_short_version"),
+
+		TotalTimeout: time.Duration(totalTimeoutSeconds) * time.Second,
+
+		LogFilePath: os.Getenv("log_file_path"),
+
+		AllowEmptyResponse: os.Getenv("allow_empty_response") != "false",
+
+		BuildIdentifier: os.Getenv("build_identifier"),
+
+		AppIDPath: os.Getenv("app_id_path"),
+		AppIDKey:  os.Getenv("app_id_key"),
+
+		BuildTimestamp: os.Getenv("build_timestamp"),
+
+		PreUploadCommand:  os.Getenv("pre_upload_command"),
+		PostUploadCommand: os.Getenv("post_upload_command"),
+
+		LockFilePath: os.Getenv("lock_file_path"),
+		LockTimeout:  time.Duration(lockTimeoutSeconds) * time.Second,
+
+		ExpectedPackageName:     os.Getenv("expected_package_name"),
+		VerifySigningCertSHA256: os.Getenv("verify_signing_cert_sha256"),
+		ReadManifest:            os.Getenv("read_manifest") == "true",
+
+		JSONStatusToStderr: os.Getenv("json_status_to_stderr") == "true",
+
+		ExportFields: splitCommaSeparatedList(os.Getenv("export_fields")),
+
+		LogConfig: os.Getenv("log_config") != "false",
+
+		APIFlavor: os.Getenv("api_flavor"),
+
+		ConnectTimeout:      parseOptionalSeconds("connect_timeout"),
+		TLSHandshakeTimeout: parseOptionalSeconds("tls_handshake_timeout"),
+
+		DeepLinkTemplate: os.Getenv("deep_link_template"),
+
+		PartialFailureMode: os.Getenv("partial_failure_mode"),
+
+		WorkingDir: os.Getenv("working_dir"),
+
+		ExtraHeaders: os.Getenv("extra_headers"),
+
+		OutputKeyPrefix: os.Getenv("output_key_prefix"),
+
+		Metadata: os.Getenv("metadata"),
+
+		EmitStepSummary: os.Getenv("emit_step_summary") == "true",
+
+		ExpectContinue: os.Getenv("expect_continue") == "true",
+
+		HMACSecret: os.Getenv("hmac_secret"),
+		HMACHeader: os.Getenv("hmac_header"),
+
+		VerifyServerChecksum: os.Getenv("verify_server_checksum") == "true",
+
+		ApkPathCandidates: splitNewlineSeparatedList(os.Getenv("apk_path_candidates")),
+
+		WarmupConnection: os.Getenv("warmup_connection") == "true",
+
+		TraceOutputPath: os.Getenv("trace_output_path"),
+
+		MetricsPushgatewayURL: os.Getenv("metrics_pushgateway_url"),
+		MetricsLabels:         os.Getenv("metrics_labels"),
+
+		MinSDKRequired:  minSDKRequired,
+		MinSDKCheckMode: os.Getenv("min_sdk_check_mode"),
+
+		WarningsAsErrors: os.Getenv("warnings_as_errors") == "true",
+
+		ParallelUploads: os.Getenv("parallel_uploads") == "true",
+
+		RequireZipalign: os.Getenv("require_zipalign") == "true",
+
+		CredentialsFile: os.Getenv("credentials_file"),
+
+		WaitForProcessing: os.Getenv("wait_for_processing") == "true",
+		ProcessingTimeout: parseOptionalSeconds("processing_timeout"),
+
+		AutoDetectNotesType: os.Getenv("auto_detect_notes_type") == "true",
+
+		PrintCurl: os.Getenv("print_curl"),
+
+		RetryOnBodyRegex: os.Getenv("retry_on_body_regex"),
+
+		ShortenerURL: os.Getenv("shortener_url"),
+
+		DistributionGroupNames: splitCommaSeparatedList(os.Getenv("distribution_group_names")),
+
+		PackageToPath:     os.Getenv("package_to_path"),
+		UploadFromPackage: os.Getenv("upload_from_package"),
+
+		SlackWebhookURL:             os.Getenv("slack_webhook_url"),
+		SlackMessageTemplate:        os.Getenv("slack_message_template"),
+		SlackSuccessMessageTemplate: os.Getenv("slack_success_message_template"),
+		SlackFailureMessageTemplate: os.Getenv("slack_failure_message_template"),
+
+		NetworkConfigJSON: os.Getenv("network_config_json"),
+		ProxyURL:          os.Getenv("proxy_url"),
+		NoProxy:           splitCommaSeparatedList(os.Getenv("no_proxy_hosts")),
+		TLSMinVersion:     os.Getenv("tls_min_version"),
+
+		UploadAction: os.Getenv("upload_action"),
+
+		LogLevel: os.Getenv("log_level"),
+
+		OutputMetadataPath:      os.Getenv("output_metadata_path"),
+		OutputMetadataSelection: os.Getenv("output_metadata_selection"),
+
+		RequireDistributable: os.Getenv("require_distributable") == "true",
+
+		ValidationConcurrency: validationConcurrency,
+
+		RedactPatterns: os.Getenv("redact_patterns"),
+
+		AllowedHosts: splitCommaSeparatedList(os.Getenv("allowed_hosts")),
+
+		TempDir:       os.Getenv("temp_dir"),
+		CreateTempDir: os.Getenv("create_temp_dir") == "true",
+
+		IdempotentRetry: os.Getenv("idempotent_retry") != "false",
+
+		ExportInstallHTML:   os.Getenv("export_install_html") == "true",
+		InstallHTMLTemplate: os.Getenv("install_html_template"),
+		InstallHTMLPath:     os.Getenv("install_html_path"),
+
+		ApkURL: os.Getenv("apk_url"),
+
+		LocalizedNotes: os.Getenv("localized_notes"),
+
+		ForbidDebuggable: os.Getenv("forbid_debuggable") == "true",
+
+		NotesOverflowMode: os.Getenv("notes_overflow_mode"),
+		NotesMaxLength:    notesMaxLength,
+
+		WriteUploadManifest: os.Getenv("write_upload_manifest") == "true",
+		UploadManifestPath:  os.Getenv("upload_manifest_path"),
+
+		RequirePublicURL: os.Getenv("require_public_url") == "true",
+
+		ExtraQueryParams: os.Getenv("extra_query_params"),
+
+		MaxResponseBytes: maxResponseBytes,
+
+		EmitAnnotation: os.Getenv("emit_annotation") == "true",
+
+		PostVerify: os.Getenv("post_verify") == "true",
+
+		RetryMaxWait: time.Duration(retryMaxWaitSeconds) * time.Second,
+
+		NotesOnly: os.Getenv("notes_only") == "true",
+
+		IdleConnTimeout:  parseOptionalSeconds("idle_conn_timeout"),
+		DisableKeepAlive: os.Getenv("disable_keepalive") == "true",
 	}
 }
 
+// printStart prints the configs, or only that the step started if LogConfig is disabled.
+func (configs ConfigsModel) printStart() {
+	if configs.LogConfig {
+		configs.print()
+		return
+	}
+	printNewline()
+	infof("HockeyApp Android Deploy step started")
+}
+
 func (configs ConfigsModel) print() {
-	fmt.Println()
-	log.Infof("Configs:")
-	log.Printf(" - ApkPath: %s", configs.ApkPath)
-	log.Printf(" - MappingPath: %s", configs.MappingPath)
-	log.Printf(" - APIToken: %s", configs.APIToken)
-	log.Printf(" - AppID: %s", configs.AppID)
-	log.Printf(" - Notes: %s", configs.Notes)
-	log.Printf(" - NotesType: %s", configs.NotesType)
-	log.Printf(" - Notify: %s", configs.Notify)
-	log.Printf(" - Status: %s", configs.Status)
-	log.Printf(" - Tags: %s", configs.Tags)
-	log.Printf(" - CommitSHA: %s", configs.CommitSHA)
-	log.Printf(" - BuildServerURL: %s", configs.BuildServerURL)
-	log.Printf(" - RepositoryURL: %s", configs.RepositoryURL)
-	log.Printf(" - Mandatory: %s", configs.Mandatory)
+	printNewline()
+	infof("Configs:")
+	printf(" - ApkPath: %s", configs.ApkPath)
+	printf(" - AabPath: %s", configs.AabPath)
+	printf(" - MappingPath: %s", configs.MappingPath)
+	printf(" - APIToken: %s", configs.APIToken)
+	printf(" - AppID: %s", configs.AppID)
+	printf(" - Notes: %s", configs.Notes)
+	printf(" - NotesType: %s", configs.NotesType)
+	printf(" - Notify: %s", configs.Notify)
+	printf(" - Status: %s", configs.Status)
+	printf(" - Tags: %s", configs.Tags)
+	printf(" - CommitSHA: %s", configs.CommitSHA)
+	printf(" - AutoCommitSHA: %v", configs.AutoCommitSHA)
+	printf(" - BuildServerURL: %s", configs.BuildServerURL)
+	printf(" - RepositoryURL: %s", configs.RepositoryURL)
+	printf(" - Mandatory: %s", configs.Mandatory)
+	printf(" - IdempotencyKey: %s", configs.IdempotencyKey)
+	printf(" - OutputFormat: %s", configs.OutputFormat)
+	printf(" - DotenvPath: %s", configs.DotenvPath)
+	printf(" - StrictMode: %v", configs.StrictMode)
+	printf(" - DeployBranchFilter: %s", configs.DeployBranchFilter)
+	printf(" - CurrentBranch: %s", configs.CurrentBranch)
+	printf(" - RetryCount: %d", configs.RetryCount)
+	printf(" - RetryWait: %s", configs.RetryWait)
+	printf(" - CacheDNS: %v", configs.CacheDNS)
+	printf(" - AutoTagBuildNumber: %v", configs.AutoTagBuildNumber)
+	printf(" - BuildNumberEnv: %s", configs.BuildNumberEnv)
+	printf(" - PrintSummary: %v", configs.PrintSummary)
+	printf(" - CACertPath: %s", configs.CACertPath)
+	printf(" - UnixSocketPath: %s", configs.UnixSocketPath)
+	printf(" - RequireMapping: %v", configs.RequireMapping)
+	printf(" - CompressMapping: %v", configs.CompressMapping)
+	printf(" - TargetVersion: %s", configs.TargetVersion)
+	printf(" - TargetShortVersion: %s", configs.TargetShortVersion)
+	printf(" - TotalTimeout: %s", configs.TotalTimeout)
+	printf(" - LogFilePath: %s", configs.LogFilePath)
+	printf(" - AllowEmptyResponse: %v", configs.AllowEmptyResponse)
+	printf(" - BuildIdentifier: %s", configs.BuildIdentifier)
+	printf(" - AppIDPath: %s", configs.AppIDPath)
+	printf(" - AppIDKey: %s", configs.AppIDKey)
+	printf(" - BuildTimestamp: %s", configs.BuildTimestamp)
+	printf(" - PreUploadCommand: %s", configs.PreUploadCommand)
+	printf(" - PostUploadCommand: %s", configs.PostUploadCommand)
+	printf(" - LockFilePath: %s", configs.LockFilePath)
+	printf(" - LockTimeout: %s", configs.LockTimeout)
+	printf(" - ExpectedPackageName: %s", configs.ExpectedPackageName)
+	printf(" - VerifySigningCertSHA256: %s", configs.VerifySigningCertSHA256)
+	printf(" - ReadManifest: %v", configs.ReadManifest)
+	printf(" - JSONStatusToStderr: %v", configs.JSONStatusToStderr)
+	printf(" - ExportFields: %s", configs.ExportFields)
+	printf(" - LogConfig: %v", configs.LogConfig)
+	printf(" - APIFlavor: %s", configs.APIFlavor)
+	printf(" - ConnectTimeout: %s", configs.ConnectTimeout)
+	printf(" - TLSHandshakeTimeout: %s", configs.TLSHandshakeTimeout)
+	printf(" - DeepLinkTemplate: %s", configs.DeepLinkTemplate)
+	printf(" - PartialFailureMode: %s", configs.PartialFailureMode)
+	printf(" - WorkingDir: %s", configs.WorkingDir)
+	if headers, err := parseExtraHeaders(configs.ExtraHeaders); err == nil {
+		printf(" - ExtraHeaders: %s", printableHeaders(headers))
+	} else {
+		printf(" - ExtraHeaders: %s", redactedValue)
+	}
+	printf(" - OutputKeyPrefix: %s", configs.OutputKeyPrefix)
+	printf(" - Metadata: %s", configs.Metadata)
+	printf(" - EmitStepSummary: %v", configs.EmitStepSummary)
+	printf(" - ExpectContinue: %v", configs.ExpectContinue)
+	printf(" - HMACSecret: %s", printableSecret(configs.HMACSecret))
+	printf(" - HMACHeader: %s", configs.HMACHeader)
+	printf(" - VerifyServerChecksum: %v", configs.VerifyServerChecksum)
+	printf(" - ApkPathCandidates: %s", strings.Join(configs.ApkPathCandidates, ", "))
+	printf(" - WarmupConnection: %v", configs.WarmupConnection)
+	printf(" - TraceOutputPath: %s", configs.TraceOutputPath)
+	printf(" - MetricsPushgatewayURL: %s", printableProxyURL(configs.MetricsPushgatewayURL))
+	printf(" - MetricsLabels: %s", configs.MetricsLabels)
+	printf(" - MinSDKRequired: %d", configs.MinSDKRequired)
+	printf(" - MinSDKCheckMode: %s", configs.MinSDKCheckMode)
+	printf(" - WarningsAsErrors: %v", configs.WarningsAsErrors)
+	printf(" - ParallelUploads: %v", configs.ParallelUploads)
+	printf(" - RequireZipalign: %v", configs.RequireZipalign)
+	printf(" - CredentialsFile: %s", configs.CredentialsFile)
+	printf(" - WaitForProcessing: %v", configs.WaitForProcessing)
+	printf(" - ProcessingTimeout: %s", configs.ProcessingTimeout)
+	printf(" - AutoDetectNotesType: %v", configs.AutoDetectNotesType)
+	printf(" - PrintCurl: %s", configs.PrintCurl)
+	printf(" - RetryOnBodyRegex: %s", configs.RetryOnBodyRegex)
+	printf(" - ShortenerURL: %s", printableProxyURL(configs.ShortenerURL))
+	printf(" - DistributionGroupNames: %s", strings.Join(configs.DistributionGroupNames, ","))
+	printf(" - PackageToPath: %s", configs.PackageToPath)
+	printf(" - UploadFromPackage: %s", configs.UploadFromPackage)
+	printf(" - SlackWebhookURL: %s", printableSecret(configs.SlackWebhookURL))
+	printf(" - SlackMessageTemplate: %s", configs.SlackMessageTemplate)
+	printf(" - SlackSuccessMessageTemplate: %s", configs.SlackSuccessMessageTemplate)
+	printf(" - SlackFailureMessageTemplate: %s", configs.SlackFailureMessageTemplate)
+	printf(" - ProxyURL: %s", printableProxyURL(configs.ProxyURL))
+	printf(" - NoProxy: %s", strings.Join(configs.NoProxy, ","))
+	printf(" - TLSMinVersion: %s", configs.TLSMinVersion)
+	printf(" - UploadAction: %s", configs.UploadAction)
+	printf(" - LogLevel: %s", configs.LogLevel)
+	printf(" - OutputMetadataPath: %s", configs.OutputMetadataPath)
+	printf(" - OutputMetadataSelection: %s", configs.OutputMetadataSelection)
+	printf(" - RequireDistributable: %v", configs.RequireDistributable)
+	printf(" - ValidationConcurrency: %d", configs.ValidationConcurrency)
+	printf(" - RedactPatterns: %s", strings.Join(splitNewlineSeparatedList(configs.RedactPatterns), ", "))
+	printf(" - AllowedHosts: %s", strings.Join(configs.AllowedHosts, ","))
+	printf(" - TempDir: %s", configs.TempDir)
+	printf(" - CreateTempDir: %v", configs.CreateTempDir)
+	printf(" - IdempotentRetry: %v", configs.IdempotentRetry)
+	printf(" - ExportInstallHTML: %v", configs.ExportInstallHTML)
+	printf(" - InstallHTMLTemplate: %s", configs.InstallHTMLTemplate)
+	printf(" - InstallHTMLPath: %s", configs.InstallHTMLPath)
+	printf(" - ApkURL: %s", printableProxyURL(configs.ApkURL))
+	printf(" - LocalizedNotes: %s", configs.LocalizedNotes)
+	printf(" - ForbidDebuggable: %v", configs.ForbidDebuggable)
+	printf(" - NotesOverflowMode: %s", configs.NotesOverflowMode)
+	printf(" - NotesMaxLength: %d", configs.NotesMaxLength)
+	printf(" - WriteUploadManifest: %v", configs.WriteUploadManifest)
+	printf(" - UploadManifestPath: %s", configs.UploadManifestPath)
+	printf(" - RequirePublicURL: %v", configs.RequirePublicURL)
+	printf(" - ExtraQueryParams: %s", configs.ExtraQueryParams)
+	printf(" - MaxResponseBytes: %d", configs.MaxResponseBytes)
+	printf(" - EmitAnnotation: %v", configs.EmitAnnotation)
+	printf(" - PostVerify: %v", configs.PostVerify)
+	printf(" - RetryMaxWait: %s", configs.RetryMaxWait)
+	printf(" - NotesOnly: %v", configs.NotesOnly)
+	printf(" - IdleConnTimeout: %s", configs.IdleConnTimeout)
+	printf(" - DisableKeepAlive: %v", configs.DisableKeepAlive)
+}
+
+// validationErrors collects every input issue, so they can be reported at once.
+type validationErrors []error
+
+func (errs validationErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	lines := []string{fmt.Sprintf("%d issues found:", len(errs))}
+	for _, err := range errs {
+		lines = append(lines, " - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
 }
 
+// validate returns every issue found with the inputs.
 func (configs ConfigsModel) validate() error {
-	if len(configs.ApkPath) == 0 {
-		return errors.New("no ApkPath parameter specified")
+	var errs validationErrors
+
+	if _, ok := logLevels[configs.LogLevel]; configs.LogLevel != "" && !ok {
+		errs = append(errs, fmt.Errorf("invalid LogLevel: %s, it should be error, warn, info or debug", configs.LogLevel))
+	}
+
+	switch configs.OutputMetadataSelection {
+	case "", outputMetadataSelectionAll, outputMetadataSelectionUniversal:
+	default:
+		errs = append(errs, fmt.Errorf("invalid OutputMetadataSelection: %s", configs.OutputMetadataSelection))
+	}
+
+	switch configs.UploadAction {
+	case "", uploadActionCreate, uploadActionUpdate:
+	default:
+		errs = append(errs, fmt.Errorf("invalid UploadAction: %s", configs.UploadAction))
+	}
+
+	if configs.NotesOnly {
+		if configs.AppID == "" {
+			errs = append(errs, errors.New("no AppID parameter specified, it is required if NotesOnly is enabled"))
+		} else if !appIDRegexp.MatchString(configs.AppID) {
+			errs = append(errs, fmt.Errorf("invalid AppID: %s, it should be 32 hexadecimal characters", configs.AppID))
+		}
+		if configs.TargetVersion == "" && configs.TargetShortVersion == "" {
+			errs = append(errs, errors.New("no TargetVersion or TargetShortVersion parameter specified, one of them is required if NotesOnly is enabled"))
+		}
+		if configs.UploadFromPackage != "" {
+			errs = append(errs, errors.New("invalid NotesOnly, it can not be used together with UploadFromPackage"))
+		}
+	} else if configs.UploadAction == uploadActionUpdate {
+		if configs.AppID == "" {
+			errs = append(errs, errors.New("no AppID parameter specified, it is required if UploadAction is update"))
+		}
+		if configs.isMappingOnly() && configs.MappingPath == "" {
+			errs = append(errs, errors.New("no ApkPath, AabPath or MappingPath parameter specified, one of them is required if UploadAction is update"))
+		}
+	} else if configs.isMappingOnly() {
+		if configs.AppID == "" {
+			errs = append(errs, errors.New("no AppID parameter specified, it is required if TargetVersion or TargetShortVersion is set"))
+		}
+		if configs.MappingPath == "" {
+			errs = append(errs, errors.New("no MappingPath parameter specified, it is required if TargetVersion or TargetShortVersion is set"))
+		}
+	} else if len(configs.ApkPath) == 0 && len(configs.AabPath) == 0 && configs.UploadFromPackage == "" {
+		errs = append(errs, errors.New("no ApkPath or AabPath parameter specified"))
+	}
+
+	if configs.PackageToPath != "" {
+		if configs.UploadFromPackage != "" {
+			errs = append(errs, errors.New("invalid PackageToPath, it can not be used together with UploadFromPackage"))
+		}
+		if configs.APIFlavor == apiFlavorAppCenter || configs.isMappingOnly() || configs.UploadAction == uploadActionUpdate {
+			errs = append(errs, errors.New("invalid PackageToPath, it is only supported for the HockeyApp app upload"))
+		}
 	}
 
 	for _, apkPath := range configs.ApkPath {
 		if exist, err := pathutil.IsPathExists(apkPath); err != nil {
-			return fmt.Errorf("failed to check if ApkPath exist at: %s, error: %v", apkPath, err)
+			errs = append(errs, fmt.Errorf("failed to check if ApkPath exist at: %s, error: %v", apkPath, err))
 		} else if !exist {
-			return fmt.Errorf("apkPath not exist at: %s", apkPath)
+			errs = append(errs, fmt.Errorf("apkPath not exist at: %s", apkPath))
+		}
+	}
+
+	for _, aabPath := range configs.AabPath {
+		if exist, err := pathutil.IsPathExists(aabPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to check if AabPath exist at: %s, error: %v", aabPath, err))
+		} else if !exist {
+			errs = append(errs, fmt.Errorf("aabPath not exist at: %s", aabPath))
 		}
 	}
 
@@ -121,183 +827,1361 @@ func (configs ConfigsModel) validate() error {
 		"Status":    configs.Status,
 		"Mandatory": configs.Mandatory,
 	}
-	for k, v := range required {
-		if v == "" {
-			return fmt.Errorf("no %s parameter specified", k)
-		}
+	if configs.PackageToPath != "" {
+		delete(required, "APIToken")
 	}
-
-	if configs.MappingPath != "" {
-		if exist, err := pathutil.IsPathExists(configs.MappingPath); err != nil {
-			return fmt.Errorf("failed to check if MappingPath exist at: %s, error: %v", configs.MappingPath, err)
-		} else if !exist {
-			return fmt.Errorf("mappingPath not exist at: %s", configs.MappingPath)
+	for _, k := range sortedKeys(required) {
+		if required[k] == "" {
+			errs = append(errs, fmt.Errorf("no %s parameter specified", k))
 		}
 	}
 
-	return nil
-}
-
-// ResponseModel ...
-type ResponseModel struct {
-	ConfigURL string `json:"config_url"`
-	PublicURL string `json:"public_url"`
-	BuildURL  string `json:"build_url"`
-}
-
-func exportEnvironmentWithEnvman(keyStr, valueStr string) error {
-	cmd := command.New("envman", "add", "--key", keyStr)
-	cmd.SetStdin(strings.NewReader(valueStr))
-	return cmd.Run()
-}
-
-func createRequest(url string, fields, files map[string]string) (*http.Request, error) {
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-
-	for key, value := range fields {
-		if err := w.WriteField(key, value); err != nil {
-			return nil, err
+	allowed := map[string][]string{
+		"NotesType": {"0", "1"},
+		"Notify":    {"0", "1", "2"},
+		"Status":    {"1", "2"},
+	}
+	for _, k := range sortedKeys(required) {
+		if values, ok := allowed[k]; ok && required[k] != "" && !contains(values, required[k]) {
+			errs = append(errs, fmt.Errorf("invalid %s: %s, it should be one of: %s", k, required[k], strings.Join(values, ", ")))
 		}
 	}
 
-	for key, file := range files {
-		f, err := os.Open(file)
-		if err != nil {
-			return nil, err
+	switch configs.APIFlavor {
+	case "", apiFlavorHockeyApp:
+	case apiFlavorAppCenter:
+		if _, _, err := appCenterApp(configs.AppID); err != nil {
+			errs = append(errs, err)
 		}
-		fw, err := w.CreateFormFile(key, file)
-		if err != nil {
-			return nil, err
+		if configs.isMappingOnly() {
+			errs = append(errs, errors.New("TargetVersion and TargetShortVersion are not supported with the appcenter APIFlavor"))
+		}
+		if configs.UploadAction == uploadActionUpdate {
+			errs = append(errs, errors.New("invalid UploadAction, update is not supported with the appcenter APIFlavor"))
 		}
-		if _, err = io.Copy(fw, f); err != nil {
-			return nil, err
+		if configs.NotesOnly {
+			errs = append(errs, errors.New("invalid NotesOnly, it is not supported with the appcenter APIFlavor"))
 		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid APIFlavor: %s", configs.APIFlavor))
 	}
 
-	if err := w.Close(); err != nil {
-		return nil, err
+	switch configs.PartialFailureMode {
+	case "", partialFailureModeFail, partialFailureModeWarn:
+	default:
+		errs = append(errs, fmt.Errorf("invalid PartialFailureMode: %s", configs.PartialFailureMode))
 	}
 
-	req, err := http.NewRequest("POST", url, &b)
-	if err != nil {
-		return nil, err
+	switch configs.OutputFormat {
+	case "", outputFormatEnvman, outputFormatGithub:
+	case outputFormatDotenv:
+		if configs.DotenvPath == "" {
+			errs = append(errs, errors.New("no DotenvPath parameter specified"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid OutputFormat: %s", configs.OutputFormat))
 	}
 
-	req.Header.Set("Content-Type", w.FormDataContentType())
+	switch configs.PrintCurl {
+	case "", printCurlDisabled, printCurlEnabled, printCurlOnly:
+	default:
+		errs = append(errs, fmt.Errorf("invalid PrintCurl: %s", configs.PrintCurl))
+	}
+
+	if configs.OutputKeyPrefix != "" && !outputKeyPrefixPattern.MatchString(configs.OutputKeyPrefix) {
+		errs = append(errs, fmt.Errorf("invalid OutputKeyPrefix: %s, it should contain only letters, digits and underscores, and should not start with a digit", configs.OutputKeyPrefix))
+	}
+
+	if len(configs.DeployBranchFilter) > 0 {
+		if configs.CurrentBranch == "" {
+			errs = append(errs, errors.New("no CurrentBranch parameter specified, it is required if DeployBranchFilter is set"))
+		}
+		for _, pattern := range configs.DeployBranchFilter {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, fmt.Errorf("invalid DeployBranchFilter pattern: %s, error: %v", pattern, err))
+			}
+		}
+	}
+
+	if configs.RetryCount < 0 {
+		errs = append(errs, errors.New("invalid RetryCount, it should be a non-negative integer"))
+	}
+	if configs.RetryWait < 0 {
+		errs = append(errs, errors.New("invalid RetryWait, it should be a non-negative integer"))
+	}
+	if configs.RetryMaxWait < 0 {
+		errs = append(errs, errors.New("invalid RetryMaxWait, it should be a non-negative integer"))
+	}
+	if configs.LockFilePath != "" && configs.LockTimeout < 0 {
+		errs = append(errs, errors.New("invalid LockTimeout, it should be a non-negative integer"))
+	}
+	if configs.ConnectTimeout < 0 {
+		errs = append(errs, errors.New("invalid ConnectTimeout, it should be a non-negative integer"))
+	}
+	if configs.TLSHandshakeTimeout < 0 {
+		errs = append(errs, errors.New("invalid TLSHandshakeTimeout, it should be a non-negative integer"))
+	}
+	if configs.IdleConnTimeout < 0 {
+		errs = append(errs, errors.New("invalid IdleConnTimeout, it should be a non-negative integer"))
+	}
+	if configs.TotalTimeout < 0 {
+		errs = append(errs, errors.New("invalid TotalTimeout, it should be a non-negative integer"))
+	}
+	if configs.ProcessingTimeout < 0 {
+		errs = append(errs, errors.New("invalid ProcessingTimeout, it should be a non-negative integer"))
+	}
+	if configs.WaitForProcessing && configs.ProcessingTimeout == 0 {
+		errs = append(errs, errors.New("no ProcessingTimeout parameter specified, it is required if WaitForProcessing is enabled"))
+	}
+	if configs.WaitForProcessing && configs.AppID == "" {
+		errs = append(errs, errors.New("no AppID parameter specified, it is required if WaitForProcessing is enabled"))
+	}
+	if configs.ValidationConcurrency < 0 {
+		errs = append(errs, errors.New("invalid ValidationConcurrency, it should be a non-negative integer"))
+	}
+	if configs.MinSDKRequired < 0 {
+		errs = append(errs, errors.New("invalid MinSDKRequired, it should be a non-negative integer"))
+	}
+	if configs.MaxResponseBytes < 0 {
+		errs = append(errs, errors.New("invalid MaxResponseBytes, it should be a non-negative integer"))
+	}
+	if configs.NotesMaxLength < 0 {
+		errs = append(errs, errors.New("invalid NotesMaxLength, it should be a non-negative integer"))
+	}
+	if configs.WriteUploadManifest && configs.UploadManifestPath == "" {
+		errs = append(errs, errors.New("no UploadManifestPath parameter specified, it is required if WriteUploadManifest is enabled"))
+	}
+	switch configs.NotesOverflowMode {
+	case "", notesOverflowModeFail, notesOverflowModeTruncate, notesOverflowModeWarn:
+	default:
+		errs = append(errs, fmt.Errorf("invalid NotesOverflowMode: %s", configs.NotesOverflowMode))
+	}
+	switch configs.MinSDKCheckMode {
+	case "", minSDKCheckModeAtMost, minSDKCheckModeAtLeast:
+	default:
+		errs = append(errs, fmt.Errorf("invalid MinSDKCheckMode: %s", configs.MinSDKCheckMode))
+	}
+
+	if configs.PostVerify {
+		if configs.AppID == "" {
+			errs = append(errs, errors.New("no AppID parameter specified, it is required if PostVerify is enabled"))
+		}
+		if configs.APIFlavor == apiFlavorAppCenter {
+			errs = append(errs, errors.New("invalid PostVerify, it is not supported with the appcenter APIFlavor"))
+		}
+	}
+
+	if len(configs.DistributionGroupNames) > 0 {
+		if configs.AppID == "" {
+			errs = append(errs, errors.New("no AppID parameter specified, it is required if DistributionGroupNames is set"))
+		}
+	}
+
+	if configs.AutoTagBuildNumber && configs.BuildNumberEnv == "" {
+		errs = append(errs, errors.New("no BuildNumberEnv parameter specified, it is required if AutoTagBuildNumber is enabled"))
+	}
+
+	if configs.ProxyURL != "" {
+		if u, err := url.Parse(configs.ProxyURL); err != nil || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid ProxyURL: %s", printableProxyURL(configs.ProxyURL)))
+		} else if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+			errs = append(errs, fmt.Errorf("invalid ProxyURL scheme: %s, it should be http, https or socks5", u.Scheme))
+		}
+	}
+	if _, ok := tlsVersions[configs.TLSMinVersion]; configs.TLSMinVersion != "" && !ok {
+		errs = append(errs, fmt.Errorf("invalid TLSMinVersion: %s, it should be 1.0, 1.1, 1.2 or 1.3", configs.TLSMinVersion))
+	}
+
+	if configs.CACertPath != "" {
+		if exist, err := pathutil.IsPathExists(configs.CACertPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to check if CACertPath exist at: %s, error: %v", configs.CACertPath, err))
+		} else if !exist {
+			errs = append(errs, fmt.Errorf("caCertPath not exist at: %s", configs.CACertPath))
+		}
+	}
+
+	if _, err := parseBuildTimestamp(configs.BuildTimestamp, time.Now()); err != nil {
+		errs = append(errs, err)
+	}
+
+	if configs.UnixSocketPath != "" {
+		if info, err := os.Stat(configs.UnixSocketPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to check if UnixSocketPath exist at: %s, error: %v", configs.UnixSocketPath, err))
+		} else if info.Mode()&os.ModeSocket == 0 {
+			errs = append(errs, fmt.Errorf("unixSocketPath is not a socket: %s", configs.UnixSocketPath))
+		}
+	}
+
+	if configs.RequireMapping && configs.MappingPath == "" {
+		errs = append(errs, errors.New("no MappingPath parameter specified, it is required if RequireMapping is enabled"))
+	}
+
+	if configs.MappingPath != "" {
+		if exist, err := pathutil.IsPathExists(configs.MappingPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to check if MappingPath exist at: %s, error: %v", configs.MappingPath, err))
+		} else if !exist {
+			errs = append(errs, fmt.Errorf("mappingPath not exist at: %s", configs.MappingPath))
+		}
+	}
+
+	if configs.VerifySigningCertSHA256 != "" && normalizeFingerprint(configs.VerifySigningCertSHA256) == "" {
+		errs = append(errs, fmt.Errorf("invalid VerifySigningCertSHA256: %s, it should be a hex encoded SHA-256 fingerprint", configs.VerifySigningCertSHA256))
+	}
+
+	if _, err := parseExtraHeaders(configs.ExtraHeaders); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := parseMetadata(configs.Metadata); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := parseLocalizedNotes(configs.LocalizedNotes); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := parseExtraQueryParams(configs.ExtraQueryParams); err != nil {
+		errs = append(errs, err)
+	}
+
+	if configs.RetryOnBodyRegex != "" {
+		if _, err := regexp.Compile(configs.RetryOnBodyRegex); err != nil {
+			errs = append(errs, fmt.Errorf("invalid RetryOnBodyRegex: %s, error: %v", configs.RetryOnBodyRegex, err))
+		}
+	}
+
+	if _, err := parseMetricsLabels(configs.MetricsLabels); err != nil {
+		errs = append(errs, err)
+	}
+
+	if configs.HMACSecret != "" {
+		if configs.HMACHeader == "" {
+			errs = append(errs, errors.New("no HMACHeader parameter specified, it is required if HMACSecret is set"))
+		}
+		if !isValidHeaderName(configs.HMACHeader) {
+			errs = append(errs, fmt.Errorf("invalid HMACHeader: %q", configs.HMACHeader))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// releaseTags returns the tags of the release, the metadata tags and the build number (if AutoTagBuildNumber is enabled)
+// are appended to the user specified tags.
+func (configs ConfigsModel) releaseTags() string {
+	tags := splitCommaSeparatedList(configs.Tags)
+	metadata, _ := parseMetadata(configs.Metadata)
+	for _, tag := range metadataTags(metadata) {
+		if !contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	if !configs.AutoTagBuildNumber {
+		return strings.Join(tags, ",")
+	}
+
+	buildNumber := strings.TrimSpace(os.Getenv(configs.BuildNumberEnv))
+	if buildNumber == "" {
+		warnf("%s is empty, the build number tag is not added", configs.BuildNumberEnv)
+	} else if !contains(tags, buildNumber) {
+		tags = append(tags, buildNumber)
+	}
+	return strings.Join(tags, ",")
+}
+
+// buildIdentifierMarker prefixes the build identifier in the release notes,
+// as the HockeyApp upload API has no custom metadata field.
+const buildIdentifierMarker = "Build-Identifier:"
+
+// releaseNotes returns the notes of the release, with the build identifier appended if set.
+func (configs ConfigsModel) releaseNotes() string {
+	if configs.BuildIdentifier == "" {
+		return configs.Notes
+	}
+	marker := fmt.Sprintf("%s %s", buildIdentifierMarker, configs.BuildIdentifier)
+	if configs.Notes == "" {
+		return marker
+	}
+	return configs.Notes + "\n\n" + marker
+}
+
+// parseBuildTimestamp parses the RFC3339 or unix epoch (seconds) timestamp,
+// now is returned if the timestamp is empty.
+func parseBuildTimestamp(timestamp string, now time.Time) (time.Time, error) {
+	timestamp = strings.TrimSpace(timestamp)
+	if timestamp == "" {
+		return now, nil
+	}
+	if epoch, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+		return time.Unix(epoch, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid BuildTimestamp: %s, it should be an RFC3339 or a unix epoch timestamp", timestamp)
+	}
+	return t, nil
+}
+
+// ArtifactModel ...
+type ArtifactModel struct {
+	Type  string
+	Path  string
+	Field string
+}
+
+const (
+	uploadActionCreate = "create"
+	uploadActionUpdate = "update"
+)
+
+const (
+	artifactTypeAPK     = "apk"
+	artifactTypeAAB     = "aab"
+	artifactTypeMapping = "mapping"
+)
+
+// artifactFields maps the artifact types to the HockeyApp upload API's file field.
+var artifactFields = map[string]string{
+	artifactTypeAPK:     "ipa",
+	artifactTypeAAB:     "ipa",
+	artifactTypeMapping: "dsym",
+}
+
+// isMappingOnly reports whether only the mapping should be attached to an existing version:
+// if TargetVersion or TargetShortVersion is set, or no APK or AAB is set with the update UploadAction.
+func (configs ConfigsModel) isMappingOnly() bool {
+	if configs.UploadAction == uploadActionUpdate {
+		return len(configs.ApkPath) == 0 && len(configs.AabPath) == 0
+	}
+	return configs.TargetVersion != "" || configs.TargetShortVersion != ""
+}
+
+// isSeparateMappingUpload reports whether the mapping is uploaded in its own request, instead of together with the APK/AAB:
+// if ParallelUploads is enabled for a HockeyApp app upload with AppID.
+func (configs ConfigsModel) isSeparateMappingUpload() bool {
+	return configs.ParallelUploads && configs.MappingPath != "" && configs.AppID != "" && !configs.NotesOnly && !configs.isMappingOnly() &&
+		configs.APIFlavor != apiFlavorAppCenter && configs.PackageToPath == "" && configs.UploadFromPackage == ""
+}
+
+func (configs ConfigsModel) artifacts() []ArtifactModel {
+	if configs.NotesOnly {
+		return nil
+	}
+	if configs.isMappingOnly() {
+		return []ArtifactModel{{Type: artifactTypeMapping, Path: configs.MappingPath, Field: artifactFields[artifactTypeMapping]}}
+	}
+
+	artifacts := []ArtifactModel{}
+	for _, pth := range configs.ApkPath {
+		artifacts = append(artifacts, ArtifactModel{Type: artifactTypeAPK, Path: pth, Field: artifactFields[artifactTypeAPK]})
+	}
+	for _, pth := range configs.AabPath {
+		artifacts = append(artifacts, ArtifactModel{Type: artifactTypeAAB, Path: pth, Field: artifactFields[artifactTypeAAB]})
+	}
+	return artifacts
+}
+
+// notifyStatusConflict returns an error if testers would be notified about a version they can not download.
+func (configs ConfigsModel) notifyStatusConflict() error {
+	if (configs.Notify == "1" || configs.Notify == "2") && configs.Status == "1" {
+		return fmt.Errorf("notify is %s (notify testers) but status is %s (download not allowed): set status to 2 to make the version downloadable, or set notify to 0 to not notify testers", configs.Notify, configs.Status)
+	}
+	return nil
+}
+
+// ResponseModel ...
+type ResponseModel struct {
+	ID        int    `json:"id"`
+	ConfigURL string `json:"config_url"`
+	PublicURL string `json:"public_url"`
+	BuildURL  string `json:"build_url"`
+
+	ShortVersion string `json:"shortversion"`
+	Status       int    `json:"status"`
+
+	UploadStats UploadStatsModel    `json:"-"`
+	Checksum    string              `json:"-"`
+	RequestURL  string              `json:"-"`
+	LocationURL string              `json:"-"`
+	Files       []UploadedFileModel `json:"-"`
+}
+
+// printableRequestURL returns the request URL without the embedded credentials.
+func printableRequestURL(requestURL *url.URL) string {
+	u := *requestURL
+	u.User = nil
+	return u.String()
+}
+
+// writeFormFile writes the file part and returns the SHA-256 checksum of the file,
+// calculated while copying it into the part.
+func writeFormFile(w *multipart.Writer, key, file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			warnf("Failed to close file (%s), error: %v", file, err)
+		}
+	}()
+
+	fw, err := createFormFilePart(w, key, file)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(fw, io.TeeReader(f, h)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// multipartBoundary is the boundary of the multipart request bodies, a random boundary is used if empty.
+// Setting it (together with the sorted parts) makes the request body reproducible.
+var multipartBoundary string
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// createRequest returns the multipart request and the SHA-256 checksums of the files (by path),
+// calculated while reading the files into the request body.
+func createRequest(method, url string, fields, files map[string]string, reporter ProgressReporter) (*http.Request, map[string]string, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	if multipartBoundary != "" {
+		if err := w.SetBoundary(multipartBoundary); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, key := range sortedKeys(fields) {
+		if err := w.WriteField(key, fields[key]); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	checksums := map[string]string{}
+	for _, key := range sortedKeys(files) {
+		checksum, err := writeFormFile(w, key, files[key])
+		if err != nil {
+			return nil, nil, err
+		}
+		checksums[files[key]] = checksum
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	var body io.Reader = &b
+	contentLength := int64(b.Len())
+	if reporter != nil {
+		body = &progressReader{reader: &b, total: contentLength, reporter: reporter}
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.ContentLength = contentLength
+
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return req, checksums, nil
+}
+
+func generateIdempotencyKey() (string, error) {
+	uuid := make([]byte, 16)
+	if _, err := rand.Read(uuid); err != nil {
+		return "", err
+	}
+	// RFC 4122 version 4, variant 1
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+// idempotencyKey returns the key identifying the upload of the artifact at the given index,
+// it is the same for every attempt of the same upload.
+func idempotencyKey(index, artifactCount int) (string, error) {
+	if configs.IdempotencyKey == "" {
+		return generateIdempotencyKey()
+	}
+	if artifactCount > 1 {
+		return fmt.Sprintf("%s-%d", configs.IdempotencyKey, index), nil
+	}
+	return configs.IdempotencyKey, nil
+}
+
+// quotaExceededSignatures are the (lowercased) fragments of the HockeyApp error response
+// sent when the account ran out of storage.
+var quotaExceededSignatures = []string{
+	"quota exceeded",
+	"storage quota",
+	"storage limit",
+	"out of storage",
+}
+
+func isQuotaExceededResponse(body []byte) bool {
+	lowerBody := strings.ToLower(string(body))
+	for _, signature := range quotaExceededSignatures {
+		if strings.Contains(lowerBody, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// deploy uploads the artifact, the reporter is optional.
+func deploy(ctx context.Context, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	printNewline()
+	infof("Performing request (%s: %s)", artifact.Type, artifact.Path)
+
+	if artifact.Type == artifactTypeMapping {
+		return deployMapping(ctx, artifact, idempotencyKey, reporter)
+	}
+	if configs.APIFlavor == apiFlavorAppCenter {
+		return deployToAppCenter(ctx, artifact, idempotencyKey, reporter)
+	}
+
+	method := "POST"
+	requestURL, fields, files := uploadRequest(artifact)
+	if configs.UploadAction == uploadActionUpdate {
+		client, err := sharedHTTPClient()
+		if err != nil {
+			return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+		}
+		version, err := targetAppVersion(ctx, client)
+		if err != nil {
+			return ResponseModel{}, err
+		}
+		printf("Updating version: %s (%s), id: %d", version.ShortVersion, version.Version, version.ID)
+		method, requestURL = "PUT", appVersionURL(version)
+	}
+	if configs.PrintCurl == printCurlEnabled {
+		printCurl(method, requestURL, fields, files)
+	}
+
+	mappingPath := files[artifactFields[artifactTypeMapping]]
+	if mappingPath != "" && configs.CompressMapping && !isCompressedMappingRejected() {
+		var cleanup func()
+		mappingPath, cleanup = compressedMapping(configs.MappingPath)
+		defer cleanup()
+		files[artifactFields[artifactTypeMapping]] = mappingPath
+	}
+
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+	responseModel, err := performRequestWithRetry(ctx, client, multipartRequest(method, requestURL, fields, files, reporter), artifact, idempotencyKey, reporter)
+
+	if mappingPath != "" && mappingPath != configs.MappingPath && isCompressedMappingRejection(err) {
+		warnf("Compressed mapping upload is not supported by the server, retrying with the uncompressed mapping")
+		setCompressedMappingRejected()
+		key, err := uncompressedIdempotencyKey(idempotencyKey)
+		if err != nil {
+			return ResponseModel{}, fmt.Errorf("Failed to generate idempotency key, error: %v", err)
+		}
+		files[artifactFields[artifactTypeMapping]] = configs.MappingPath
+		return performRequestWithRetry(ctx, client, multipartRequest(method, requestURL, fields, files, reporter), artifact, key, reporter)
+	}
+	return responseModel, err
+}
+
+// uploadRequest returns the URL, the form fields and the files of the HockeyApp upload request of the artifact.
+func uploadRequest(artifact ArtifactModel) (string, map[string]string, map[string]string) {
+	requestURL := hockeyAppAPIURL + "/apps/upload"
+	if configs.AppID != "" {
+		requestURL = fmt.Sprintf("%s/apps/%s/app_versions/upload", hockeyAppAPIURL, configs.AppID)
+	}
+
+	fields := map[string]string{
+		"notes":            configs.releaseNotes(),
+		"notes_type":       configs.NotesType,
+		"notify":           configs.Notify,
+		"status":           configs.Status,
+		"mandatory":        configs.Mandatory,
+		"tags":             configs.releaseTags(),
+		"commit_sha":       configs.CommitSHA,
+		"build_server_url": configs.BuildServerURL,
+		"repository_url":   configs.RepositoryURL,
+		"timestamp":        strconv.FormatInt(configs.buildTime.Unix(), 10),
+	}
+
+	if len(configs.teamIDs) > 0 {
+		fields["teams"] = strings.Join(configs.teamIDs, ",")
+	}
+
+	files := map[string]string{
+		artifact.Field: artifact.Path,
+	}
+	if configs.MappingPath != "" && !configs.isSeparateMappingUpload() {
+		files[artifactFields[artifactTypeMapping]] = configs.MappingPath
+	}
+	return requestURL, fields, files
+}
+
+// attemptCount is the number of upload attempts made by the step.
+var attemptCount int
+
+// expectContinueRejected is set if the server rejected the Expect: 100-continue header.
+var expectContinueRejected bool
+
+// runStateMutex guards the run state (attemptCount, expectContinueRejected, lastStatusCode, warnings)
+// updated by the parallel uploads.
+var runStateMutex sync.Mutex
+
+// requestBuilder creates a new upload request for every attempt,
+// returning the request and the SHA-256 checksums of the uploaded files by path.
+type requestBuilder func() (*http.Request, map[string]string, error)
+
+// multipartRequest returns the builder of the multipart upload request with the given fields and files.
+func multipartRequest(method, requestURL string, fields, files map[string]string, reporter ProgressReporter) requestBuilder {
+	return func() (*http.Request, map[string]string, error) {
+		return createRequest(method, requestURL, fields, files, reporter)
+	}
+}
+
+func performRequestWithRetry(ctx context.Context, client *http.Client, newRequest requestBuilder, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	var responseModel ResponseModel
+	attempts, err := retryRequest(ctx, isIdempotentUpload(), func() error {
+		countAttempt()
+		var err error
+		responseModel, err = performRequest(ctx, client, newRequest, artifact, idempotencyKey, reporter)
+		return err
+	})
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	printf("Upload succeeded after %d attempt(s)", attempts)
+	return responseModel, nil
+}
+
+func performRequest(ctx context.Context, client *http.Client, newRequest requestBuilder, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	request, checksums, err := newRequest()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create request, error: %v", err)
+	}
+	if reporter != nil {
+		reporter.OnValidated(artifact)
+	}
+
+	setExtraHeaders(request)
+	setExtraQueryParams(request)
+	signRequest(request, time.Now())
+	request.Header.Add("X-HockeyAppToken", configs.APIToken)
+	request.Header.Add("Idempotency-Key", idempotencyKey)
+	runStateMutex.Lock()
+	expectContinue := configs.ExpectContinue && !expectContinueRejected
+	runStateMutex.Unlock()
+	if expectContinue {
+		request.Header.Set("Expect", "100-continue")
+	}
+	var requestSent int32
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				atomic.StoreInt32(&requestSent, 1)
+			}
+		},
+	}
+	uploadStart := time.Now()
+	response, err := client.Do(request.WithContext(httptrace.WithClientTrace(ctx, trace)))
+	if err != nil {
+		err = fmt.Errorf("Performing request failed, error: %w", err)
+		if atomic.LoadInt32(&requestSent) == 1 {
+			return ResponseModel{}, requestSentError{err: err}
+		}
+		return ResponseModel{}, err
+	}
+	uploadStats := UploadStatsModel{Size: request.ContentLength, Duration: time.Since(uploadStart)}
+	setLastStatusCode(response.StatusCode)
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+
+	if response.StatusCode == http.StatusExpectationFailed && expectContinue {
+		warnf("The server does not support the Expect: 100-continue header, uploading without it")
+		runStateMutex.Lock()
+		expectContinueRejected = true
+		runStateMutex.Unlock()
+		return performRequest(ctx, client, newRequest, artifact, idempotencyKey, reporter)
+	}
+
+	contents, readErr := readResponseBody(response)
+	if readErr != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to read response body, error: %v", readErr)
+	} else if configs.retryBodyRegexp != nil && configs.retryBodyRegexp.Match(contents) {
+		return ResponseModel{}, bodyMatchError{StatusCode: response.StatusCode}
+	} else if isLoginPageResponse(response.Header.Get("Content-Type"), contents) {
+		return ResponseModel{}, loginPageError{StatusCode: response.StatusCode}
+	} else if response.StatusCode < 200 || response.StatusCode > 300 {
+		if isQuotaExceededResponse(contents) {
+			return ResponseModel{}, fmt.Errorf("account storage quota exceeded; prune old builds (status code: %d)", response.StatusCode)
+		}
+		if isUnsupportedEncodingResponse(contents) {
+			return ResponseModel{}, unsupportedEncodingError{StatusCode: response.StatusCode}
+		}
+		return ResponseModel{}, statusCodeError{StatusCode: response.StatusCode}
+	}
+
+	printNewline()
+	infof("Response:")
+	printf(" status code: %d", response.StatusCode)
+	printf(" body: %s", contents)
+
+	if configs.VerifyServerChecksum && artifact.Path != "" {
+		if err := verifyServerChecksum(response, artifact, checksums[artifact.Path]); err != nil {
+			return ResponseModel{}, err
+		}
+	}
+
+	responseModel := ResponseModel{}
+	if len(bytes.TrimSpace(contents)) == 0 && configs.AllowEmptyResponse {
+		warnf("Empty response body, no version metadata was returned")
+	} else if err := json.Unmarshal([]byte(contents), &responseModel); err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to parse response body, error: %v", err)
+	}
+	responseModel.UploadStats = uploadStats
+	responseModel.Checksum = checksums[artifact.Path]
+	responseModel.RequestURL = printableRequestURL(request.URL)
+	responseModel.Files = uploadedFiles(artifact, checksums)
+	if location, err := response.Location(); err == nil {
+		responseModel.LocationURL = location.String()
+		if responseModel.BuildURL == "" {
+			responseModel.BuildURL = responseModel.LocationURL
+		}
+	}
+	printf("Uploaded %s", uploadStats)
+	if reporter != nil {
+		reporter.OnComplete(responseModel)
+	}
+	return responseModel, nil
+}
+
+// versionStatusDownloadable is the status of the app versions the testers can download.
+const versionStatusDownloadable = 2
+
+// checkDistributable returns an error if the uploaded version can not be downloaded by the testers.
+func checkDistributable(responseModel ResponseModel) error {
+	switch responseModel.Status {
+	case versionStatusDownloadable:
+		return nil
+	case 0:
+		return errors.New("the response contains no version status, can not check if the version is distributable")
+	default:
+		return fmt.Errorf("the uploaded version is not distributable, status: %d (expected: %d, downloadable)", responseModel.Status, versionStatusDownloadable)
+	}
+}
+
+// checkPublicURL returns an error if no public URL was returned and a public URL is required,
+// it prints a notice about the missing public URL otherwise.
+func checkPublicURL(publicURLs []string) error {
+	if len(publicURLs) > 0 {
+		return nil
+	}
+	if configs.RequirePublicURL {
+		return errors.New("no public URL returned")
+	}
+	printf("No public URL returned")
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// isBranchDeployable reports whether the branch matches any of the glob patterns of the filter,
+// every branch is deployable if the filter is empty.
+func isBranchDeployable(branch string, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, pattern := range filter {
+		if match, err := path.Match(pattern, branch); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// deepLinkFromTemplate substitutes the public URL into the template:
+// {public_url} is replaced as is, {public_url_escaped} is query escaped.
+func deepLinkFromTemplate(template, publicURL string) string {
+	return strings.NewReplacer(
+		"{public_url_escaped}", url.QueryEscape(publicURL),
+		"{public_url}", publicURL,
+	).Replace(template)
+}
+
+// warnings are the warnings printed during the run.
+var warnings []string
+
+// warnf prints the warning (if the LogLevel allows it) and records it for WarningsAsErrors.
+func warnf(format string, v ...interface{}) {
+	if logEnabled(logLevelWarn) {
+		log.Warnf(format, v...)
+	}
+	runStateMutex.Lock()
+	warnings = append(warnings, fmt.Sprintf(format, v...))
+	runStateMutex.Unlock()
+}
+
+// warningsError returns an error if WarningsAsErrors is enabled and any warning was printed.
+func warningsError() error {
+	if !configs.WarningsAsErrors || len(warnings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d warning(s) treated as errors (warnings_as_errors is enabled):\n - %s", len(warnings), strings.Join(warnings, "\n - "))
+}
+
+// deployAbortedError is returned by deployArtifact if the step should fail regardless of the other uploads:
+// the idempotency key could not be generated, the pre upload command failed or the API token is rejected.
+type deployAbortedError struct {
+	Err error
+}
+
+func (e deployAbortedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e deployAbortedError) Unwrap() error {
+	return e.Err
+}
+
+func isDeployAbortedError(err error) bool {
+	var abortedErr deployAbortedError
+	return errors.As(err, &abortedErr)
+}
+
+// deployArtifact runs the upload hooks and deploys the i-th of the n artifacts with the uploader.
+// It can run in the upload goroutines, so it never exits: the errors which should fail the step right away
+// (e.g. the API token is rejected, as the other uploads would fail too) are returned as deployAbortedError.
+func deployArtifact(ctx context.Context, uploader Uploader, i, n int, artifact ArtifactModel, reporter ProgressReporter) (ResponseModel, error) {
+	key, err := idempotencyKey(i, n)
+	if err != nil {
+		return ResponseModel{}, deployAbortedError{Err: fmt.Errorf("failed to generate idempotency key: %v", err)}
+	}
+
+	if configs.PreUploadCommand != "" {
+		if err := runHook("pre upload", configs.PreUploadCommand, preUploadHookEnvs(artifact)); err != nil {
+			return ResponseModel{}, deployAbortedError{Err: err}
+		}
+	}
+
+	responseModel, err := uploader.Upload(ctx, i, artifact, key, reporter)
+	if isAuthError(err) {
+		return ResponseModel{}, deployAbortedError{Err: fmt.Errorf("Hockeyapp deploy failed (%s): %v", artifact.Path, err)}
+	}
+	if err != nil {
+		return ResponseModel{}, err
+	}
+
+	if configs.PostUploadCommand != "" {
+		if err := runHook("post upload", configs.PostUploadCommand, postUploadHookEnvs(artifact, responseModel)); err != nil {
+			warnf("%v", err)
+		}
+	}
+	return responseModel, nil
+}
+
+// deployArtifacts deploys the artifacts with the uploader, in parallel if parallel is set,
+// and returns the responses and the errors by artifact index once every upload finished.
+func deployArtifacts(ctx context.Context, uploader Uploader, artifacts []ArtifactModel, parallel bool) ([]ResponseModel, []error) {
+	responseModels := make([]ResponseModel, len(artifacts))
+	deployErrs := make([]error, len(artifacts))
+	if !parallel {
+		reporter := newLogReporter()
+		for i, artifact := range artifacts {
+			responseModels[i], deployErrs[i] = deployArtifact(ctx, uploader, i, len(artifacts), artifact, reporter)
+		}
+		return responseModels, deployErrs
+	}
+
+	printf("Uploading %d artifacts in parallel", len(artifacts))
+	var wg sync.WaitGroup
+	for i, artifact := range artifacts {
+		wg.Add(1)
+		go func(i int, artifact ArtifactModel) {
+			defer wg.Done()
+			responseModels[i], deployErrs[i] = deployArtifact(ctx, uploader, i, len(artifacts), artifact, newLogReporter())
+		}(i, artifact)
+	}
+	wg.Wait()
+	return responseModels, deployErrs
+}
+
+// isFatalError reports whether the error should fail the step regardless of the other uploads.
+func isFatalError(err error) bool {
+	return isDeployAbortedError(err) || isHostNotAllowedError(err)
+}
+
+// firstFatalError returns the first fatal error (a deployAbortedError or a hostNotAllowedError) of the errors.
+// The code running in goroutines (the uploads, the artifact validation) never exits, it returns the errors instead,
+// and run only returns the fatal error once they finished, so no upload is killed mid-flight.
+func firstFatalError(errs ...error) error {
+	for _, err := range errs {
+		if isFatalError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// exit terminates the step with the exit code, it is replaced in the tests.
+var exit = os.Exit
+
+func failf(format string, v ...interface{}) {
+	log.Errorf(format, v...)
+	if err := exportOutput(hockeyAppDeployStatusKey, hockeyAppDeployStatusFailed); err != nil {
+		warnf("Failed to export %s, error: %v", hockeyAppDeployStatusKey, err)
+	}
+	if attemptCount > 0 {
+		if err := exportOutput(hockeyAppDeployAttemptsKey, strconv.Itoa(attemptCount)); err != nil {
+			warnf("Failed to export %s, error: %v", hockeyAppDeployAttemptsKey, err)
+		}
+	}
+	reportStatus(StatusModel{Status: hockeyAppDeployStatusFailed, Error: fmt.Sprintf(format, v...)})
+	removeTempPaths()
+	exit(1)
+}
 
-	return req, nil
+// inputError is returned by run if an input is invalid.
+type inputError struct {
+	Err error
 }
 
-func deploy(apkPath string) (ResponseModel, error) {
-	fmt.Println()
-	log.Infof("Performing request")
+func (e inputError) Error() string {
+	return e.Err.Error()
+}
 
-	requestURL := "https://rink.hockeyapp.net/api/2/apps/upload"
-	if configs.AppID != "" {
-		requestURL = fmt.Sprintf("https://rink.hockeyapp.net/api/2/apps/%s/app_versions/upload", configs.AppID)
-	}
+func (e inputError) Unwrap() error {
+	return e.Err
+}
 
-	fields := map[string]string{
-		"notes":            configs.Notes,
-		"notes_type":       configs.NotesType,
-		"notify":           configs.Notify,
-		"status":           configs.Status,
-		"mandatory":        configs.Mandatory,
-		"tags":             configs.Tags,
-		"commit_sha":       configs.CommitSHA,
-		"build_server_url": configs.BuildServerURL,
-		"repository_url":   configs.RepositoryURL,
+// fail fails the step with the error returned by run.
+func fail(err error) {
+	var inputErr inputError
+	if errors.As(err, &inputErr) {
+		failWithInputError(inputErr.Err)
+		return
 	}
+	failf("%v", err)
+}
 
-	files := map[string]string{
-		"ipa": apkPath,
+func failWithInputError(err error) {
+	log.Errorf("Issue with input: %s", err)
+	reportStatus(StatusModel{Status: hockeyAppDeployStatusFailed, Error: fmt.Sprintf("Issue with input: %s", err)})
+	removeTempPaths()
+	exit(1)
+}
+
+func main() {
+	if err := applyProfile(); err != nil {
+		failWithInputError(err)
 	}
-	if configs.MappingPath != "" {
-		files["dsym"] = configs.MappingPath
+	configs = createConfigsModelFromEnvs()
+	if err := configs.applyNetworkConfig(); err != nil {
+		failWithInputError(err)
 	}
-
-	request, err := createRequest(requestURL, fields, files)
+	if err := configs.applyWorkingDir(); err != nil {
+		failWithInputError(err)
+	}
+	if err := configs.applyTempDir(); err != nil {
+		failWithInputError(err)
+	}
+	redactPatterns, err := parseRedactPatterns(configs.RedactPatterns)
 	if err != nil {
-		return ResponseModel{}, fmt.Errorf("Failed to create request, error: %v", err)
+		failWithInputError(err)
 	}
+	configs.redactPatterns = redactPatterns
+	setLogOutput(os.Stdout)
+	cleanupOnInterrupt()
+	defer removeTempPaths()
 
-	request.Header.Add("X-HockeyAppToken", configs.APIToken)
-	client := http.Client{}
-	response, err := client.Do(request)
-	if err != nil {
-		return ResponseModel{}, fmt.Errorf("Performing request failed, error: %v", err)
+	if configs.LogFilePath != "" {
+		closeLogFile, err := teeLogToFile(configs.LogFilePath)
+		if err != nil {
+			warnf("Failed to open log file at: %s, error: %v", configs.LogFilePath, err)
+		} else {
+			defer func() {
+				if err := closeLogFile(); err != nil {
+					warnf("Failed to close log file, error: %v", err)
+				}
+			}()
+		}
 	}
-	defer func() {
-		if err := response.Body.Close(); err != nil {
-			log.Warnf("Failed to close response body, error: %v", err)
+
+	configs.printStart()
+
+	var uploader Uploader = httpUploader{}
+	if configs.UploadFromPackage != "" {
+		requestPackage, err := readRequestPackage(configs.UploadFromPackage)
+		if err != nil {
+			failf("%v", err)
 		}
-	}()
+		uploader = packagedUploader{requestPackage: requestPackage}
+	}
 
-	contents, readErr := ioutil.ReadAll(response.Body)
-	if readErr != nil {
-		return ResponseModel{}, fmt.Errorf("Failed to read response body, error: %v", readErr)
-	} else if response.StatusCode < 200 || response.StatusCode > 300 {
-		return ResponseModel{}, fmt.Errorf("Performing request failed, status code: %d", response.StatusCode)
+	if err := run(uploader); err != nil {
+		fail(err)
 	}
+}
 
-	log.Donef("Request succeeded")
-	fmt.Println()
-	log.Infof("Response:")
-	log.Printf(" status code: %d", response.StatusCode)
-	log.Printf(" body: %s", contents)
+// run deploys the artifacts with the uploader, once the configs are loaded and the logging is set up.
+// It returns an inputError if an input is invalid, and an error if the deploy failed.
+func run(uploader Uploader) error {
+	ctx := context.Background()
+	if configs.TotalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, configs.TotalTimeout)
+		defer cancel()
+	}
 
-	responseModel := ResponseModel{}
-	if err := json.Unmarshal([]byte(contents), &responseModel); err != nil {
-		return ResponseModel{}, fmt.Errorf("Failed to parse response body, error: %v", err)
+	if err := resolveInputs(ctx); err != nil {
+		return err
+	}
+	if err := checkArtifactsBeforeDeploy(); err != nil {
+		return err
 	}
-	return responseModel, nil
-}
 
-func contains(list []string, item string) bool {
-	for _, i := range list {
-		if i == item {
-			return true
+	if !isBranchDeployable(configs.CurrentBranch, configs.DeployBranchFilter) {
+		noticef("Skipping deploy: branch (%s) does not match the deploy branch filter (%s)", configs.CurrentBranch, strings.Join(configs.DeployBranchFilter, ","))
+		if err := exportOutputs(map[string]string{hockeyAppDeployStatusKey: hockeyAppDeployStatusSuccess}); err != nil {
+			return err
 		}
+		reportStatus(StatusModel{Status: hockeyAppDeployStatusSuccess})
+		return nil
 	}
-	return false
-}
 
-func main() {
-	configs = createConfigsModelFromEnvs()
-	configs.print()
-	if err := configs.validate(); err != nil {
-		log.Errorf("Issue with input: %s", err)
-		os.Exit(1)
+	if len(configs.DistributionGroupNames) > 0 && configs.APIFlavor != apiFlavorAppCenter {
+		client, err := sharedHTTPClient()
+		if err != nil {
+			return fmt.Errorf("Failed to create HTTP client, error: %v", err)
+		}
+		teams, err := fetchAppTeams(ctx, client, configs.AppID)
+		if err != nil {
+			return fmt.Errorf("Failed to fetch the distribution groups: %v", err)
+		}
+		if configs.teamIDs, err = resolveTeamIDs(teams, configs.DistributionGroupNames); err != nil {
+			return fmt.Errorf("Failed to resolve the distribution groups: %v", err)
+		}
+		printf("Distribution group IDs: %s", strings.Join(configs.teamIDs, ","))
+	}
+
+	if configs.PrintCurl == printCurlOnly {
+		for _, artifact := range configs.artifacts() {
+			if artifact.Type == artifactTypeMapping || configs.APIFlavor == apiFlavorAppCenter || configs.UploadAction == uploadActionUpdate {
+				warnf("Printing the curl command is only supported for the HockeyApp app upload, skipping: %s", artifact.Path)
+				continue
+			}
+			requestURL, fields, files := uploadRequest(artifact)
+			printCurl("POST", requestURL, fields, files)
+		}
+		noticef("Skipping deploy: print_curl is set to only")
+		if err := exportOutputs(map[string]string{hockeyAppDeployStatusKey: hockeyAppDeployStatusSuccess}); err != nil {
+			return err
+		}
+		reportStatus(StatusModel{Status: hockeyAppDeployStatusSuccess})
+		return nil
+	}
+
+	if configs.PackageToPath != "" {
+		if err := os.MkdirAll(configs.PackageToPath, 0755); err != nil {
+			return fmt.Errorf("Failed to create the package directory: %v", err)
+		}
+		artifacts := configs.artifacts()
+		requestPackage := RequestPackageModel{}
+		for i, artifact := range artifacts {
+			key, err := idempotencyKey(i, len(artifacts))
+			if err != nil {
+				return fmt.Errorf("Failed to generate idempotency key: %v", err)
+			}
+			requestURL, fields, files := uploadRequest(artifact)
+			packaged, err := packageRequest(configs.PackageToPath, i, "POST", requestURL, fields, files, artifact, key)
+			if err != nil {
+				return fmt.Errorf("Failed to package the request (%s): %v", artifact.Path, err)
+			}
+			requestPackage.Requests = append(requestPackage.Requests, packaged)
+		}
+		if err := writeRequestPackage(configs.PackageToPath, requestPackage); err != nil {
+			return fmt.Errorf("Failed to write the request package: %v", err)
+		}
+		donef("Requests packaged to: %s", configs.PackageToPath)
+		noticef("Skipping deploy: package_to_path is set, upload the package with upload_from_package")
+		if err := exportOutputs(map[string]string{hockeyAppDeployStatusKey: hockeyAppDeployStatusSuccess}); err != nil {
+			return err
+		}
+		reportStatus(StatusModel{Status: hockeyAppDeployStatusSuccess})
+		return nil
+	}
+
+	if configs.NotesOnly {
+		responseModel, err := deployNotes(ctx)
+		if err != nil {
+			return fmt.Errorf("Hockeyapp deploy failed: %v", err)
+		}
+		donef("Notes updated: %s (%d)", responseModel.ShortVersion, responseModel.ID)
+		outputs := map[string]string{hockeyAppDeployStatusKey: hockeyAppDeployStatusSuccess}
+		if responseModel.PublicURL != "" {
+			outputs[hockeyAppDeployPublicURLKey] = responseModel.PublicURL
+		}
+		if err := exportOutputs(outputs); err != nil {
+			return err
+		}
+		reportStatus(StatusModel{Status: hockeyAppDeployStatusSuccess, PublicURL: responseModel.PublicURL})
+		return nil
 	}
 
-	log.Warnf("This step is deprecated as HockeyApp is shutting down, see https://www.hockeyapp.net/blog/2019/11/16/hockeyApp-is-being-retired.html.")
+	noticef("This step is deprecated as HockeyApp is shutting down, see https://www.hockeyapp.net/blog/2019/11/16/hockeyApp-is-being-retired.html.")
 
 	configURLs := []string{}
 	buildURLs := []string{}
 	publicURLs := []string{}
+	uploadStats := UploadStatsModel{}
+	checksum := ""
+	processingState := ""
+	requestURL := ""
+	locationURL := ""
+	uploadManifest := UploadManifestModel{Files: []UploadedFileModel{}}
+	version := ""
+	var manifest *ManifestModel
 
-	for _, apkPath := range configs.ApkPath {
-		responseModel, err := deploy(apkPath)
+	artifacts := configs.artifacts()
+
+	required, err := requiredDiskSpace(artifacts, configs.MappingPath)
+	if err != nil {
+		return fmt.Errorf("Failed to calculate the required disk space: %v", err)
+	}
+	if err := checkFreeDiskSpace(tempDir(), required); err != nil {
+		return err
+	}
+
+	if u, ok := uploader.(packagedUploader); ok {
+		artifacts = u.artifacts()
+		printf("Uploading %d packaged request(s) from: %s", len(artifacts), configs.UploadFromPackage)
+	}
+
+	if configs.LockFilePath != "" {
+		printf("Acquiring lock: %s", configs.LockFilePath)
+		lock, err := acquireFileLock(configs.LockFilePath, configs.LockTimeout)
+		if err != nil {
+			return fmt.Errorf("Failed to acquire lock: %v", err)
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				warnf("Failed to release lock, error: %v", err)
+			}
+		}()
+	}
+
+	if configs.CacheDNS {
+		if _, err := sharedHTTPClient(); err != nil {
+			warnf("Failed to create HTTP client, error: %v", err)
+		} else if dnsCache != nil {
+			if err := dnsCache.resolveUpFront(ctx, configs.apiHost()); err != nil {
+				warnf("Failed to resolve %s up front, error: %v", configs.apiHost(), err)
+			}
+		}
+	}
+
+	if configs.WarmupConnection {
+		apiURL := hockeyAppAPIURL
+		if configs.APIFlavor == apiFlavorAppCenter {
+			apiURL = appCenterAPIURL
+		}
+		if client, err := sharedHTTPClient(); err != nil {
+			warnf("Failed to create HTTP client, error: %v", err)
+		} else if err := warmUpConnection(ctx, client, apiURL); err != nil {
+			if isFatalError(err) {
+				return err
+			}
+			warnf("Failed to warm up the connection, error: %v", err)
+		}
+	}
+
+	var mappingResponseModels []ResponseModel
+	var mappingErrs []error
+	separateMapping := configs.isSeparateMappingUpload()
+	// An existing version can be updated with the mapping in parallel with the APK/AAB upload.
+	parallelMapping := separateMapping && configs.UploadAction == uploadActionUpdate
+	var mappingDone chan struct{}
+	if parallelMapping {
+		printf("Uploading the mapping in parallel")
+		mappingResponseModels, mappingErrs = make([]ResponseModel, 1), make([]error, 1)
+		mappingDone = make(chan struct{})
+		go func() {
+			defer close(mappingDone)
+			mappingResponseModels[0], mappingErrs[0] = uploadSeparateMapping(ctx, len(artifacts), len(artifacts)+1, nil)
+		}()
+	}
+	responseModels, deployErrs := deployArtifacts(ctx, uploader, artifacts, configs.ParallelUploads && len(artifacts) > 1)
+	if parallelMapping {
+		<-mappingDone
+	}
+	if err := firstFatalError(deployErrs...); err != nil {
+		return err
+	}
+	if separateMapping && !parallelMapping {
+		// The mapping can only be attached to an existing version, so it is uploaded after the versions are created.
+		versionIDs := []int{}
+		seen := map[int]bool{}
+		for i, responseModel := range responseModels {
+			if deployErrs[i] == nil && responseModel.ID != 0 && !seen[responseModel.ID] {
+				seen[responseModel.ID] = true
+				versionIDs = append(versionIDs, responseModel.ID)
+			}
+		}
+		for j, versionID := range versionIDs {
+			id := versionID
+			responseModel, err := uploadSeparateMapping(ctx, len(artifacts)+j, len(artifacts)+len(versionIDs), &id)
+			mappingResponseModels = append(mappingResponseModels, responseModel)
+			mappingErrs = append(mappingErrs, err)
+		}
+	}
+
+	if err := firstFatalError(mappingErrs...); err != nil {
+		return err
+	}
+
+	results := []DeployResultModel{}
+	for i, artifact := range artifacts {
+		responseModel, err := responseModels[i], deployErrs[i]
+		results = append(results, DeployResultModel{Artifact: artifact, Err: err})
 		if err != nil {
-			log.Errorf("Hockeyapp deploy failed: %v", err)
-			if err := exportEnvironmentWithEnvman(hockeyAppDeployStatusKey, hockeyAppDeployStatusFailed); err != nil {
-				log.Warnf("Failed to export %s, error: %v", hockeyAppDeployStatusKey, err)
+			log.Errorf("Hockeyapp deploy failed (%s): %v", artifact.Path, err)
+			continue
+		}
+
+		uploadStats = uploadStats.Add(responseModel.UploadStats)
+		uploadManifest.Files = append(uploadManifest.Files, responseModel.Files...)
+		if responseModel.ShortVersion != "" {
+			version = responseModel.ShortVersion
+		}
+		if responseModel.Checksum != "" {
+			checksum = responseModel.Checksum
+			printf("Artifact SHA-256: %s", checksum)
+		}
+		if responseModel.RequestURL != "" {
+			requestURL = responseModel.RequestURL
+			printf("Request URL: %s", requestURL)
+		}
+		if responseModel.LocationURL != "" {
+			locationURL = responseModel.LocationURL
+			printf("Location URL: %s", locationURL)
+		}
+
+		if configs.RequireDistributable && configs.APIFlavor != apiFlavorAppCenter {
+			if err := checkDistributable(responseModel); err != nil {
+				return fmt.Errorf("Hockeyapp deploy failed (%s): %v", artifact.Path, err)
+			}
+		}
+
+		if configs.PostVerify && responseModel.ID != 0 {
+			if artifact.Type == artifactTypeMapping {
+				warnf("Upload verification skipped (%s): the version metadata does not describe the mapping", artifact.Path)
+			} else if err := verifyUploadedVersion(ctx, responseModel.ID, artifact); err != nil {
+				if configs.StrictMode {
+					return fmt.Errorf("Upload verification failed: %v", err)
+				}
+				warnf("Upload verification failed: %v", err)
+			} else {
+				donef("Version %d verified", responseModel.ID)
+			}
+		}
+
+		if configs.WaitForProcessing && configs.APIFlavor != apiFlavorAppCenter && responseModel.ID != 0 {
+			state, err := waitForProcessing(ctx, responseModel.ID, configs.ProcessingTimeout)
+			if err != nil {
+				warnf("Failed to check the processing status: %v", err)
+			} else if state == processingStateTimeout {
+				warnf("Version %d is not processed after %s, the URLs might not be usable yet", responseModel.ID, configs.ProcessingTimeout)
+			} else {
+				donef("Version %d is processed", responseModel.ID)
+			}
+			processingState = state
+		}
+
+		if configs.ReadManifest && artifact.Type == artifactTypeAPK {
+			m, err := readAPKManifest(artifact.Path)
+			if err != nil {
+				warnf("Failed to read the manifest: %v", err)
+			} else {
+				manifest = &m
+				donef("Version code: %s, version name: %s", m.VersionCode, m.VersionName)
 			}
-			os.Exit(1)
 		}
 		if responseModel.ConfigURL != "" && !contains(configURLs, responseModel.ConfigURL) {
 			configURLs = append(configURLs, responseModel.ConfigURL)
-			log.Donef("Config URL: %s", responseModel.ConfigURL)
+			donef("Config URL: %s", responseModel.ConfigURL)
 		}
 		if responseModel.BuildURL != "" && !contains(buildURLs, responseModel.BuildURL) {
 			buildURLs = append(buildURLs, responseModel.BuildURL)
-			log.Donef("Build (direct download) URL: %s", responseModel.BuildURL)
+			donef("Build (direct download) URL: %s", responseModel.BuildURL)
 		}
 		if responseModel.PublicURL != "" && !contains(publicURLs, responseModel.PublicURL) {
 			publicURLs = append(publicURLs, responseModel.PublicURL)
-			log.Donef("Public URL: %s", responseModel.PublicURL)
+			donef("Public URL: %s", responseModel.PublicURL)
+		}
+	}
+
+	for i, responseModel := range mappingResponseModels {
+		mappingArtifact := ArtifactModel{Type: artifactTypeMapping, Path: configs.MappingPath, Field: artifactFields[artifactTypeMapping]}
+		results = append(results, DeployResultModel{Artifact: mappingArtifact, Err: mappingErrs[i]})
+		if mappingErrs[i] != nil {
+			log.Errorf("Hockeyapp mapping upload failed (%s): %v", mappingArtifact.Path, mappingErrs[i])
+			continue
+		}
+		uploadStats = uploadStats.Add(responseModel.UploadStats)
+		uploadManifest.Files = append(uploadManifest.Files, responseModel.Files...)
+		donef("Mapping attached to version: %d", responseModel.ID)
+	}
+
+	partial := isPartialDeploy(results)
+	if failed := failedDeployCount(results); failed > 0 {
+		if len(results) > 1 {
+			printDeployBreakdown(results)
+		}
+		if failed == len(results) || configs.PartialFailureMode != partialFailureModeWarn {
+			for k, v := range map[string]string{
+				hockeyAppDeployPartialKey:   strconv.FormatBool(partial),
+				hockeyAppDeployStatusMapKey: deployStatusMap(results),
+			} {
+				if err := exportOutput(k, v); err != nil {
+					warnf("Failed to export %s, error: %v", k, err)
+				}
+			}
+			return fmt.Errorf("Hockeyapp deploy failed: %d of %d upload(s) failed", failed, len(results))
 		}
+		warnf("%d of %d upload(s) failed", failed, len(results))
+	}
+	if err := checkPublicURL(publicURLs); err != nil {
+		return fmt.Errorf("Hockeyapp deploy failed: %v", err)
+	}
+	if len(buildURLs) == 0 {
+		printf("No build (direct download) URL returned")
+	}
+	if err := warningsError(); err != nil {
+		return err
 	}
 
 	outputs := map[string]string{
@@ -305,6 +2189,11 @@ func main() {
 		hockeyAppDeployConfigURLKeyList: strings.Join(configURLs, "|"),
 		hockeyAppDeployBuildURLKeyList:  strings.Join(buildURLs, "|"),
 		hockeyAppDeployPublicURLKeyList: strings.Join(publicURLs, "|"),
+		hockeyAppDeployUploadSpeedKey:   fmt.Sprintf("%.2f", uploadStats.SpeedMBps()),
+		hockeyAppDeployUploadSizeKey:    strconv.FormatInt(uploadStats.Size, 10),
+		hockeyAppDeployAttemptsKey:      strconv.Itoa(attemptCount),
+		hockeyAppDeployPartialKey:       strconv.FormatBool(partial),
+		hockeyAppDeployStatusMapKey:     deployStatusMap(results),
 	}
 	if len(configURLs) > 0 {
 		outputs[hockeyAppDeployConfigURLKey] = configURLs[len(configURLs)-1]
@@ -316,9 +2205,315 @@ func main() {
 		outputs[hockeyAppDeployPublicURLKey] = publicURLs[len(publicURLs)-1]
 	}
 
-	for k, v := range outputs {
-		if err := exportEnvironmentWithEnvman(k, v); err != nil {
-			log.Warnf("Failed to export %s, error: %v", k, err)
+	if configs.DeepLinkTemplate != "" && len(publicURLs) > 0 {
+		deepLink := deepLinkFromTemplate(configs.DeepLinkTemplate, publicURLs[len(publicURLs)-1])
+		outputs[hockeyAppDeployDeepLinkKey] = deepLink
+		donef("Deep link: %s", deepLink)
+	}
+
+	if configs.ShortenerURL != "" && len(publicURLs) > 0 {
+		if client, err := sharedHTTPClient(); err != nil {
+			warnf("Failed to create HTTP client, error: %v", err)
+		} else if shortURL, err := shortenURL(ctx, client, configs.ShortenerURL, publicURLs[len(publicURLs)-1]); err != nil {
+			if isFatalError(err) {
+				return err
+			}
+			warnf("Failed to shorten the public URL, error: %v", err)
+		} else {
+			outputs[hockeyAppDeployShortURLKey] = shortURL
+			donef("Short URL: %s", shortURL)
+		}
+	}
+
+	if checksum != "" {
+		outputs[hockeyAppDeployChecksumKey] = checksum
+	}
+	if processingState != "" {
+		outputs[hockeyAppDeployProcessingKey] = processingState
+	}
+	if requestURL != "" {
+		outputs[hockeyAppDeployRequestURLKey] = requestURL
+	}
+	if locationURL != "" {
+		outputs[hockeyAppDeployLocationURLKey] = locationURL
+	}
+
+	if manifest == nil && configs.outputMetadataManifest != nil {
+		manifest = configs.outputMetadataManifest
+		donef("Version code: %s, version name: %s (from the output metadata)", manifest.VersionCode, manifest.VersionName)
+	}
+	if manifest != nil {
+		outputs[hockeyAppDeployVersionCodeKey] = manifest.VersionCode
+		outputs[hockeyAppDeployVersionNameKey] = manifest.VersionName
+		if version == "" {
+			version = manifest.VersionName
+		}
+	}
+
+	if configs.WriteUploadManifest {
+		if err := writeUploadManifest(configs.UploadManifestPath, uploadManifest); err != nil {
+			warnf("Failed to write the upload manifest to: %s, error: %v", configs.UploadManifestPath, err)
+		} else {
+			donef("Upload manifest written to: %s", configs.UploadManifestPath)
+		}
+	}
+
+	if configs.ExportInstallHTML && (outputs[hockeyAppDeployPublicURLKey] != "" || outputs[hockeyAppDeployBuildURLKey] != "") {
+		installHTML := renderInstallHTML(configs.InstallHTMLTemplate, version, outputs[hockeyAppDeployPublicURLKey], outputs[hockeyAppDeployBuildURLKey])
+		outputs[hockeyAppDeployInstallHTMLKey] = installHTML
+		if configs.InstallHTMLPath != "" {
+			if err := ioutil.WriteFile(configs.InstallHTMLPath, []byte(installHTML), 0644); err != nil {
+				warnf("Failed to write the install HTML to: %s, error: %v", configs.InstallHTMLPath, err)
+			} else {
+				donef("Install HTML written to: %s", configs.InstallHTMLPath)
+			}
+		}
+	}
+
+	printf("Total upload: %s", uploadStats)
+	printf("Upload attempts: %d", attemptCount)
+
+	exportFields := configs.ExportFields
+	if len(exportFields) == 0 {
+		exportFields = defaultExportFields
+	}
+	exports := filterResponseOutputs(outputs, exportFields)
+	if err := exportOutputs(exports); err != nil {
+		return err
+	}
+
+	if configs.PrintSummary {
+		printSummary(exports, configs.secrets())
+	}
+
+	if configs.EmitStepSummary {
+		markdown := stepSummaryMarkdown(results, manifest, outputs[hockeyAppDeployPublicURLKey], outputs[hockeyAppDeployBuildURLKey])
+		if err := writeStepSummary(markdown); err != nil {
+			warnf("Failed to write the step summary, error: %v", err)
+		}
+	}
+
+	reportStatus(StatusModel{
+		Status:    hockeyAppDeployStatusSuccess,
+		PublicURL: outputs[hockeyAppDeployPublicURLKey],
+		BuildURL:  outputs[hockeyAppDeployBuildURLKey],
+		Version:   version,
+	})
+	return nil
+}
+
+// resolveInputs completes the configs from the inputs pointing to other sources
+// (the app ID file, the credentials file, the standard input, the APK URL, the output metadata),
+// validates them and parses the derived configs.
+func resolveInputs(ctx context.Context) error {
+	if configs.AppID == "" && configs.AppIDPath != "" {
+		appID, err := readAppID(configs.AppIDPath, configs.AppIDKey)
+		if err != nil {
+			return inputError{Err: err}
+		}
+		configs.AppID = appID
+		printf("App ID read from: %s", configs.AppIDPath)
+	}
+
+	if configs.APIToken == "" && configs.CredentialsFile != "" {
+		host := configs.apiHost()
+		token, found, err := netrcPassword(configs.CredentialsFile, host)
+		if err != nil {
+			return inputError{Err: err}
+		}
+		if !found {
+			return inputError{Err: fmt.Errorf("no credentials found for %s in the credentials file: %s", host, configs.CredentialsFile)}
+		}
+		configs.APIToken = token
+		printf("API token read from the credentials file for: %s", host)
+	}
+
+	stdinUsed := false
+	for i, pth := range configs.ApkPath {
+		if pth != stdinPath {
+			continue
+		}
+		if stdinUsed {
+			return inputError{Err: errors.New("invalid ApkPath, the standard input (-) can only be read once")}
+		}
+		stdinUsed = true
+		printf("Reading the APK from the standard input")
+		apkPath, err := readArtifactFromStdin()
+		if err != nil {
+			return inputError{Err: fmt.Errorf("failed to read the APK from the standard input, error: %v", err)}
+		}
+		configs.ApkPath[i] = apkPath
+	}
+
+	if configs.ApkURL != "" {
+		printf("Downloading the APK from: %s", printableProxyURL(configs.ApkURL))
+		client, err := sharedHTTPClient()
+		if err != nil {
+			return fmt.Errorf("Failed to create HTTP client, error: %v", err)
+		}
+		apkPath, err := downloadArtifact(ctx, client, configs.ApkURL)
+		if err != nil {
+			return inputError{Err: fmt.Errorf("failed to download the APK from: %s, error: %v", printableProxyURL(configs.ApkURL), err)}
+		}
+		configs.ApkPath = append(configs.ApkPath, apkPath)
+	}
+
+	if len(configs.ApkPath) == 0 && configs.OutputMetadataPath != "" {
+		apkPaths, m, err := readOutputMetadata(configs.OutputMetadataPath, configs.OutputMetadataSelection)
+		if err != nil {
+			return inputError{Err: err}
+		}
+		configs.ApkPath = apkPaths
+		configs.outputMetadataManifest = &m
+		printf("APK path(s) read from the output metadata: %s", strings.Join(apkPaths, ", "))
+	}
+
+	if len(configs.ApkPath) == 0 && len(configs.ApkPathCandidates) > 0 {
+		pth, err := firstExistingPath(configs.ApkPathCandidates)
+		if err != nil {
+			return inputError{Err: err}
+		}
+		configs.ApkPath = []string{pth}
+		printf("APK path selected from the candidates: %s", pth)
+	}
+
+	if configs.NotesType == "" {
+		configs.NotesType = notesTypeText
+		if configs.AutoDetectNotesType {
+			configs.NotesType = detectNotesType(configs.Notes)
+			printf("Notes type detected: %s (markdown: %v)", configs.NotesType, configs.NotesType == notesTypeMarkdown)
+		}
+	}
+
+	if err := configs.validate(); err != nil {
+		return inputError{Err: err}
+	}
+	configs.buildTime, _ = parseBuildTimestamp(configs.BuildTimestamp, time.Now())
+	configs.extraHeaders, _ = parseExtraHeaders(configs.ExtraHeaders)
+	configs.extraQueryParams, _ = parseExtraQueryParams(configs.ExtraQueryParams)
+	localizedNotes, _ := parseLocalizedNotes(configs.LocalizedNotes)
+	notes, err := fitNotes(notesWithLocalizedNotes(configs.Notes, localizedNotes, configs.NotesType), configs.NotesMaxLength, configs.NotesOverflowMode)
+	if err != nil {
+		return err
+	}
+	configs.Notes = notes
+	if configs.RetryOnBodyRegex != "" {
+		configs.retryBodyRegexp = regexp.MustCompile(configs.RetryOnBodyRegex)
+	}
+
+	if metadata, _ := parseMetadata(configs.Metadata); len(metadata) > 0 {
+		printf("Metadata tags: %s", strings.Join(metadataTags(metadata), ","))
+	}
+
+	if configs.AutoCommitSHA && configs.CommitSHA == "" {
+		if sha, err := gitCommitSHA(configs.WorkingDir); err != nil {
+			warnf("Failed to read the commit SHA from git: %v", err)
+		} else {
+			configs.CommitSHA = sha
+			printf("Commit SHA read from git: %s", configs.CommitSHA)
+		}
+	}
+	return nil
+}
+
+// checkArtifactsBeforeDeploy runs the enabled checks of the mapping and the artifacts.
+func checkArtifactsBeforeDeploy() error {
+	if configs.MappingPath != "" {
+		if err := checkMappingFile(configs.MappingPath); err != nil {
+			if configs.StrictMode {
+				return inputError{Err: err}
+			}
+			warnf("%s", err)
+		}
+	}
+
+	if err := configs.notifyStatusConflict(); err != nil {
+		if configs.StrictMode {
+			return inputError{Err: err}
+		}
+		warnf("%s", err)
+	}
+
+	if configs.ExpectedPackageName != "" {
+		for _, artifact := range configs.artifacts() {
+			if artifact.Type != artifactTypeAPK {
+				warnf("Package name check is only supported for APKs, skipping: %s", artifact.Path)
+				continue
+			}
+			manifest, err := readAPKManifest(artifact.Path)
+			if err != nil {
+				return fmt.Errorf("Failed to read the package name: %v", err)
+			}
+			if manifest.PackageName != configs.ExpectedPackageName {
+				return fmt.Errorf("Package name (%s) of %s does not match the expected package name (%s)", manifest.PackageName, artifact.Path, configs.ExpectedPackageName)
+			}
+		}
+	}
+
+	if configs.MinSDKRequired > 0 {
+		for _, artifact := range configs.artifacts() {
+			if artifact.Type != artifactTypeAPK {
+				warnf("minSdkVersion check is only supported for APKs, skipping: %s", artifact.Path)
+				continue
+			}
+			manifest, err := readAPKManifest(artifact.Path)
+			if err != nil {
+				return fmt.Errorf("Failed to read the minSdkVersion: %v", err)
+			}
+			if err := checkMinSDKVersion(manifest, configs.MinSDKRequired, configs.MinSDKCheckMode); err != nil {
+				return fmt.Errorf("%s: %v", artifact.Path, err)
+			}
+		}
+	}
+
+	if configs.ForbidDebuggable {
+		for _, artifact := range configs.artifacts() {
+			if artifact.Type != artifactTypeAPK {
+				warnf("Debuggable check is only supported for APKs, skipping: %s", artifact.Path)
+				continue
+			}
+			manifest, err := readAPKManifest(artifact.Path)
+			if err != nil {
+				return fmt.Errorf("Failed to read the debuggable flag: %v", err)
+			}
+			if manifest.Debuggable {
+				return fmt.Errorf("%s is a debuggable build (android:debuggable=true), it must not be deployed to the testers", artifact.Path)
+			}
+		}
+	}
+
+	if configs.ValidationConcurrency > 0 {
+		checksums, err := checkArtifacts(configs.artifacts(), configs.ValidationConcurrency)
+		if err != nil {
+			return fmt.Errorf("Artifact validation failed: %v", err)
+		}
+		for _, pth := range sortedKeys(checksums) {
+			printf("Artifact validated: %s (SHA-256: %s)", pth, checksums[pth])
+		}
+	}
+
+	if configs.RequireZipalign {
+		for _, artifact := range configs.artifacts() {
+			if artifact.Type != artifactTypeAPK {
+				continue
+			}
+			if err := checkZipAlign(artifact.Path); err != nil {
+				return fmt.Errorf("Zipalign check failed: %v", err)
+			}
+			printf("APK is zipaligned: %s", artifact.Path)
+		}
+	}
+
+	if configs.VerifySigningCertSHA256 != "" {
+		for _, artifact := range configs.artifacts() {
+			if artifact.Type == artifactTypeMapping {
+				continue
+			}
+			if err := verifySigningCert(artifact.Path, configs.VerifySigningCertSHA256); err != nil {
+				return fmt.Errorf("Signing certificate verification failed: %v", err)
+			}
+			printf("Signing certificate of %s verified", artifact.Path)
 		}
 	}
+	return nil
 }
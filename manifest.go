@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+const apkManifestPath = "AndroidManifest.xml"
+
+const (
+	minSDKCheckModeAtMost  = "at_most"
+	minSDKCheckModeAtLeast = "at_least"
+)
+
+// ManifestModel ...
+type ManifestModel struct {
+	PackageName   string
+	VersionCode   string
+	VersionName   string
+	MinSDKVersion string
+	Debuggable    bool
+}
+
+func manifestFromElements(elements []axmlElement) ManifestModel {
+	manifest := ManifestModel{}
+	for _, element := range elements {
+		switch element.Name {
+		case "manifest":
+			manifest.PackageName = element.Attributes["package"]
+			manifest.VersionCode = element.Attributes["versionCode"]
+			manifest.VersionName = element.Attributes["versionName"]
+		case "uses-sdk":
+			manifest.MinSDKVersion = element.Attributes["minSdkVersion"]
+		case "application":
+			manifest.Debuggable = element.Attributes["debuggable"] == "true"
+		}
+	}
+	return manifest
+}
+
+// readAPKManifest parses the binary AndroidManifest.xml of the APK.
+func readAPKManifest(apkPath string) (ManifestModel, error) {
+	r, err := zip.OpenReader(apkPath)
+	if err != nil {
+		return ManifestModel{}, fmt.Errorf("failed to open APK (%s), error: %v", apkPath, err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			warnf("Failed to close APK (%s), error: %v", apkPath, err)
+		}
+	}()
+
+	for _, f := range r.File {
+		if f.Name != apkManifestPath {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return ManifestModel{}, fmt.Errorf("failed to open %s in APK (%s), error: %v", apkManifestPath, apkPath, err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		if cerr := rc.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return ManifestModel{}, fmt.Errorf("failed to read %s in APK (%s), error: %v", apkManifestPath, apkPath, err)
+		}
+
+		elements, err := parseAXMLElements(data)
+		if err != nil {
+			return ManifestModel{}, fmt.Errorf("failed to parse %s in APK (%s), error: %v", apkManifestPath, apkPath, err)
+		}
+		return manifestFromElements(elements), nil
+	}
+
+	return ManifestModel{}, fmt.Errorf("no %s found in APK (%s)", apkManifestPath, apkPath)
+}
+
+// checkMinSDKVersion compares the minSdkVersion of the manifest with the required value:
+// in at_most mode (default) it can not be higher, in at_least mode it can not be lower than required.
+// The minSdkVersion defaults to 1 if the manifest does not declare it.
+func checkMinSDKVersion(manifest ManifestModel, required int, mode string) error {
+	minSDK := 1
+	if manifest.MinSDKVersion != "" {
+		var err error
+		if minSDK, err = strconv.Atoi(manifest.MinSDKVersion); err != nil {
+			return fmt.Errorf("invalid minSdkVersion: %s", manifest.MinSDKVersion)
+		}
+	}
+
+	if mode == minSDKCheckModeAtLeast {
+		if minSDK < required {
+			return fmt.Errorf("minSdkVersion (%d) is lower than required (%d)", minSDK, required)
+		}
+	} else if minSDK > required {
+		return fmt.Errorf("minSdkVersion (%d) is higher than allowed (%d)", minSDK, required)
+	}
+	printf("minSdkVersion: %d", minSDK)
+	return nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadAPKManifest(t *testing.T) {
+	dir := t.TempDir()
+	noManifestPath := filepath.Join(dir, "no-manifest.apk")
+	writeZip(t, noManifestPath, map[string]string{"classes.dex": "dex"})
+	notAPKPath := filepath.Join(dir, "app.txt")
+	if err := ioutil.WriteFile(notAPKPath, []byte("app"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		apkPath string
+		want    ManifestModel
+		wantErr bool
+	}{
+		{name: "manifest", apkPath: "testdata/app.apk", want: ManifestModel{PackageName: "com.example.app", VersionCode: "42", VersionName: "1.2.3", MinSDKVersion: "21", Debuggable: true}},
+		{name: "no manifest", apkPath: noManifestPath, wantErr: true},
+		{name: "not an APK", apkPath: notAPKPath, wantErr: true},
+		{name: "missing APK", apkPath: "testdata/missing.apk", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAPKManifest(tt.apkPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readAPKManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readAPKManifest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeZip(t *testing.T, pth string, entries map[string]string) {
+	f, err := os.Create(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for _, name := range sortedKeys(entries) {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(entries[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readZipEntry(t *testing.T, pth, name string) []byte {
+	r, err := zip.OpenReader(filepath.FromSlash(pth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		data, err := ioutil.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	t.Fatalf("no %s in %s", name, pth)
+	return nil
+}
+
+func TestCheckMinSDKVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		minSDKVersion string
+		required      int
+		mode          string
+		wantErr       bool
+	}{
+		{name: "at most, lower", minSDKVersion: "21", required: 23, mode: minSDKCheckModeAtMost},
+		{name: "at most, equal", minSDKVersion: "23", required: 23, mode: minSDKCheckModeAtMost},
+		{name: "at most, higher", minSDKVersion: "24", required: 23, mode: minSDKCheckModeAtMost, wantErr: true},
+		{name: "default mode, higher", minSDKVersion: "24", required: 23, wantErr: true},
+		{name: "at least, lower", minSDKVersion: "21", required: 23, mode: minSDKCheckModeAtLeast, wantErr: true},
+		{name: "at least, higher", minSDKVersion: "24", required: 23, mode: minSDKCheckModeAtLeast},
+		{name: "undeclared defaults to 1", required: 1, mode: minSDKCheckModeAtLeast},
+		{name: "undeclared, at least", required: 2, mode: minSDKCheckModeAtLeast, wantErr: true},
+		{name: "invalid", minSDKVersion: "@ref/min", required: 23, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkMinSDKVersion(ManifestModel{MinSDKVersion: tt.minSDKVersion}, tt.required, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkMinSDKVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
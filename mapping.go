@@ -0,0 +1,250 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// mappingSniffSize is the number of leading bytes inspected to decide
+// whether a file looks like a ProGuard mapping.
+const mappingSniffSize = 64 * 1024
+
+var zipMagic = []byte("PK\x03\x04")
+
+func looksLikeProguardMapping(content []byte) bool {
+	if bytes.IndexByte(content, 0) != -1 {
+		return false
+	}
+	return bytes.Contains(content, []byte("->"))
+}
+
+func readHead(r io.Reader, size int) ([]byte, error) {
+	head := make([]byte, size)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:n], nil
+}
+
+func checkZippedMapping(pth string) error {
+	r, err := zip.OpenReader(pth)
+	if err != nil {
+		return fmt.Errorf("failed to open zipped mapping file (%s), error: %v", pth, err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			warnf("Failed to close zipped mapping file (%s), error: %v", pth, err)
+		}
+	}()
+
+	for _, f := range r.File {
+		if f.Name == "AndroidManifest.xml" || strings.HasSuffix(f.Name, ".dex") {
+			return fmt.Errorf("mapping file (%s) looks like an APK, not a ProGuard mapping", pth)
+		}
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s in zipped mapping file (%s), error: %v", f.Name, pth, err)
+		}
+		head, err := readHead(rc, mappingSniffSize)
+		if cerr := rc.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s in zipped mapping file (%s), error: %v", f.Name, pth, err)
+		}
+		if looksLikeProguardMapping(head) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("zipped mapping file (%s) does not contain a ProGuard mapping", pth)
+}
+
+// checkMappingFile returns an error if the file at pth does not look like a ProGuard mapping
+// (a text file with `->` arrows) or a zip containing one.
+func checkMappingFile(pth string) error {
+	f, err := os.Open(pth)
+	if err != nil {
+		return fmt.Errorf("failed to open mapping file (%s), error: %v", pth, err)
+	}
+	head, err := readHead(f, mappingSniffSize)
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read mapping file (%s), error: %v", pth, err)
+	}
+
+	if bytes.HasPrefix(head, zipMagic) {
+		return checkZippedMapping(pth)
+	}
+
+	if !looksLikeProguardMapping(head) {
+		return fmt.Errorf("mapping file (%s) does not look like a ProGuard mapping", pth)
+	}
+	return nil
+}
+
+// gzipMapping writes the gzip compressed mapping file into a new temporary directory,
+// the returned function removes it. The directory is also removed if the step is interrupted.
+func gzipMapping(pth string) (string, func(), error) {
+	tmpDir, err := ioutil.TempDir(tempDir(), "hockeyapp-mapping")
+	if err != nil {
+		return "", nil, err
+	}
+	registerTempPath(tmpDir)
+	cleanup := func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			warnf("Failed to remove temporary directory (%s), error: %v", tmpDir, err)
+			return
+		}
+		unregisterTempPath(tmpDir)
+	}
+
+	gzPth := filepath.Join(tmpDir, filepath.Base(pth)+".gz")
+	if err := gzipFile(pth, gzPth); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return gzPth, cleanup, nil
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			warnf("Failed to close file (%s), error: %v", src, err)
+		}
+	}()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(out)
+	if _, err := io.Copy(w, in); err != nil {
+		return closeWithError(out, err)
+	}
+	if err := w.Close(); err != nil {
+		return closeWithError(out, err)
+	}
+	return out.Close()
+}
+
+// unsupportedEncodingSignatures are the lowercased substrings of the error responses
+// rejecting the Content-Encoding of the request.
+var unsupportedEncodingSignatures = []string{
+	"unsupported content-encoding",
+	"unsupported encoding",
+	"content-encoding not supported",
+	"encoding not supported",
+}
+
+// unsupportedEncodingError is returned if the server responds with an error rejecting the Content-Encoding of the request.
+type unsupportedEncodingError struct {
+	StatusCode int
+}
+
+func (e unsupportedEncodingError) Error() string {
+	return fmt.Sprintf("Performing request failed, status code: %d: the server does not support the Content-Encoding of the upload", e.StatusCode)
+}
+
+func isUnsupportedEncodingResponse(body []byte) bool {
+	lowerBody := strings.ToLower(string(body))
+	for _, signature := range unsupportedEncodingSignatures {
+		if strings.Contains(lowerBody, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressedMappingRejected is set once the server rejected the gzipped mapping,
+// the later uploads of the step send the mapping uncompressed.
+var compressedMappingRejected struct {
+	sync.Mutex
+	rejected bool
+}
+
+func isCompressedMappingRejected() bool {
+	compressedMappingRejected.Lock()
+	defer compressedMappingRejected.Unlock()
+	return compressedMappingRejected.rejected
+}
+
+func setCompressedMappingRejected() {
+	compressedMappingRejected.Lock()
+	compressedMappingRejected.rejected = true
+	compressedMappingRejected.Unlock()
+}
+
+// isCompressedMappingRejection reports whether the upload of the gzipped mapping failed
+// with 415 Unsupported Media Type, or with an error response rejecting the Content-Encoding.
+// Other client errors (like a 400 of an invalid field) are not retried uncompressed.
+func isCompressedMappingRejection(err error) bool {
+	var encodingErr unsupportedEncodingError
+	if errors.As(err, &encodingErr) {
+		return true
+	}
+	var statusErr statusCodeError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnsupportedMediaType
+}
+
+// uncompressedIdempotencyKey returns the idempotency key of the uncompressed mapping retry,
+// it differs from the key of the rejected request, so a deduplicating gateway does not replay the rejection.
+func uncompressedIdempotencyKey(key string) (string, error) {
+	if configs.IdempotencyKey == "" {
+		return generateIdempotencyKey()
+	}
+	return key + "-uncompressed", nil
+}
+
+// compressedMapping returns the path of the gzipped mapping if compressing made it smaller,
+// the original path otherwise. The returned function removes the temporary files.
+func compressedMapping(pth string) (string, func()) {
+	noop := func() {}
+	if strings.HasSuffix(strings.ToLower(pth), ".zip") {
+		return pth, noop
+	}
+
+	gzPth, cleanup, err := gzipMapping(pth)
+	if err != nil {
+		warnf("Failed to compress the mapping file, uploading it uncompressed: %v", err)
+		return pth, noop
+	}
+
+	size, err := fileSize(pth)
+	if err != nil {
+		cleanup()
+		return pth, noop
+	}
+	gzSize, err := fileSize(gzPth)
+	if err != nil || gzSize >= size {
+		printf("Compressing the mapping file does not reduce its size, uploading it uncompressed")
+		cleanup()
+		return pth, noop
+	}
+
+	printf("Mapping file compressed: %d -> %d bytes", size, gzSize)
+	return gzPth, cleanup
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testMapping = "com.example.app.MainActivity -> a.a:\n    void onCreate(android.os.Bundle) -> a\n"
+
+func TestCheckMappingFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		pth := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(pth, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return pth
+	}
+	zipped := filepath.Join(dir, "mapping.zip")
+	writeZip(t, zipped, map[string]string{"README": "symbols", "mapping.txt": testMapping})
+	zippedWithoutMapping := filepath.Join(dir, "symbols.zip")
+	writeZip(t, zippedWithoutMapping, map[string]string{"README": "symbols"})
+
+	tests := []struct {
+		name    string
+		pth     string
+		wantErr bool
+	}{
+		{name: "mapping", pth: write("mapping.txt", testMapping)},
+		{name: "zipped mapping", pth: zipped},
+		{name: "text file", pth: write("notes.txt", "release notes"), wantErr: true},
+		{name: "binary file", pth: write("mapping.bin", "a -> b\x00"), wantErr: true},
+		{name: "APK", pth: "testdata/app.apk", wantErr: true},
+		{name: "zip without mapping", pth: zippedWithoutMapping, wantErr: true},
+		{name: "missing", pth: filepath.Join(dir, "missing.txt"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkMappingFile(tt.pth); (err != nil) != tt.wantErr {
+				t.Errorf("checkMappingFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompressedMapping(t *testing.T) {
+	dir := t.TempDir()
+	large := filepath.Join(dir, "mapping.txt")
+	if err := ioutil.WriteFile(large, []byte(strings.Repeat(testMapping, 100)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	small := filepath.Join(dir, "small.txt")
+	if err := ioutil.WriteFile(small, []byte("a -> b"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	zipped := filepath.Join(dir, "mapping.zip")
+	writeZip(t, zipped, map[string]string{"mapping.txt": testMapping})
+
+	tests := []struct {
+		name           string
+		pth            string
+		wantCompressed bool
+	}{
+		{name: "compressed", pth: large, wantCompressed: true},
+		{name: "not smaller", pth: small},
+		{name: "zip", pth: zipped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{TempDir: t.TempDir()})
+			got, cleanup := compressedMapping(tt.pth)
+			if tt.wantCompressed {
+				if filepath.Base(got) != filepath.Base(tt.pth)+".gz" || fileContentType(got) != "application/gzip" {
+					t.Errorf("compressedMapping() = %s, want the gzipped %s", got, filepath.Base(tt.pth))
+				}
+			} else if got != tt.pth {
+				t.Errorf("compressedMapping() = %s, want the original path", got)
+			}
+			cleanup()
+			if _, err := os.Stat(got); tt.wantCompressed && err == nil {
+				t.Errorf("%s exists after the cleanup", got)
+			}
+		})
+	}
+}
+
+func TestIsCompressedMappingRejection(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "unsupported media type", err: fmt.Errorf("upload failed: %w", statusCodeError{StatusCode: 415}), want: true},
+		{name: "unsupported encoding response", err: unsupportedEncodingError{StatusCode: 400}, want: true},
+		{name: "bad request", err: statusCodeError{StatusCode: 400}},
+		{name: "unprocessable entity", err: statusCodeError{StatusCode: 422}},
+		{name: "server error", err: statusCodeError{StatusCode: 500}},
+		{name: "invalid token", err: statusCodeError{StatusCode: 401}},
+		{name: "network error", err: errors.New("connection reset")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCompressedMappingRejection(tt.err); got != tt.want {
+				t.Errorf("isCompressedMappingRejection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUncompressedIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		idempotencyKey string
+		key            string
+		want           string
+	}{
+		{name: "derived from the input key", idempotencyKey: "deploy-1", key: "deploy-1-2", want: "deploy-1-2-uncompressed"},
+		{name: "generated", key: "4f9c8e2a-0000-4000-8000-000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{IdempotencyKey: tt.idempotencyKey})
+			got, err := uncompressedIdempotencyKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == tt.key || (tt.want != "" && got != tt.want) {
+				t.Errorf("uncompressedIdempotencyKey() = %s, want a new key (%s)", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployCompressedMapping(t *testing.T) {
+	dir := t.TempDir()
+	mappingPth := filepath.Join(dir, "mapping.txt")
+	mapping := strings.Repeat(testMapping, 100)
+	if err := ioutil.WriteFile(mappingPth, []byte(mapping), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		rejectGzip    func(w http.ResponseWriter)
+		wantUploads   int
+		wantEncodings []string
+		wantFilenames []string
+		wantErr       bool
+		wantRejected  bool
+	}{
+		{
+			name:          "decompressed at the server",
+			wantUploads:   1,
+			wantEncodings: []string{"gzip"},
+			wantFilenames: []string{"mapping.txt.gz"},
+		},
+		{
+			name:          "unsupported media type",
+			rejectGzip:    func(w http.ResponseWriter) { w.WriteHeader(http.StatusUnsupportedMediaType) },
+			wantUploads:   2,
+			wantEncodings: []string{"gzip", ""},
+			wantFilenames: []string{"mapping.txt.gz", "mapping.txt"},
+			wantRejected:  true,
+		},
+		{
+			name: "unsupported encoding response",
+			rejectGzip: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors": {"dsym": ["unsupported Content-Encoding: gzip"]}}`))
+			},
+			wantUploads:   2,
+			wantEncodings: []string{"gzip", ""},
+			wantFilenames: []string{"mapping.txt.gz", "mapping.txt"},
+			wantRejected:  true,
+		},
+		{
+			name:          "other bad request",
+			rejectGzip:    func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadRequest) },
+			wantUploads:   1,
+			wantEncodings: []string{"gzip"},
+			wantFilenames: []string{"mapping.txt.gz"},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCompressedMappingRejected := func() {
+				compressedMappingRejected.Lock()
+				compressedMappingRejected.rejected = false
+				compressedMappingRejected.Unlock()
+			}
+			resetCompressedMappingRejected()
+			t.Cleanup(resetCompressedMappingRejected)
+
+			var encodings, filenames []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reader, err := r.MultipartReader()
+				if err != nil {
+					t.Errorf("MultipartReader() error = %v", err)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				var encoding string
+				for {
+					part, err := reader.NextPart()
+					if err == io.EOF {
+						break
+					} else if err != nil {
+						t.Errorf("NextPart() error = %v", err)
+						return
+					}
+					if part.FormName() != "dsym" {
+						continue
+					}
+					encoding = part.Header.Get("Content-Encoding")
+					encodings = append(encodings, encoding)
+					filenames = append(filenames, filepath.Base(part.FileName()))
+
+					var content io.Reader = part
+					if encoding == "gzip" {
+						gz, err := gzip.NewReader(part)
+						if err != nil {
+							t.Errorf("gzip.NewReader() error = %v", err)
+							return
+						}
+						content = gz
+					}
+					got, err := ioutil.ReadAll(content)
+					if err != nil {
+						t.Errorf("reading the mapping part failed, error: %v", err)
+						return
+					}
+					if !bytes.Equal(got, []byte(mapping)) {
+						t.Errorf("decompressed mapping part differs from the original mapping (%d bytes, want %d bytes)", len(got), len(mapping))
+					}
+				}
+				if encoding == "gzip" && tt.rejectGzip != nil {
+					tt.rejectGzip(w)
+					return
+				}
+				_, _ = w.Write([]byte(`{"id": 7, "public_url": "https://rink.hockeyapp.net/apps/app-id/app_versions/7"}`))
+			}))
+			defer server.Close()
+			setAPIURL(t, &hockeyAppAPIURL, server.URL+"/api/2")
+			setConfigs(t, ConfigsModel{AppID: "app-id", MappingPath: mappingPth, CompressMapping: true})
+
+			artifact := ArtifactModel{Type: artifactTypeAPK, Path: "testdata/app.apk", Field: artifactFields[artifactTypeAPK]}
+			_, err := deploy(context.Background(), artifact, "key", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deploy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(encodings) != tt.wantUploads {
+				t.Fatalf("uploads = %d, want %d", len(encodings), tt.wantUploads)
+			}
+			for i := range encodings {
+				if encodings[i] != tt.wantEncodings[i] {
+					t.Errorf("upload %d: mapping Content-Encoding = %q, want %q", i, encodings[i], tt.wantEncodings[i])
+				}
+				if filenames[i] != tt.wantFilenames[i] {
+					t.Errorf("upload %d: mapping filename = %s, want %s", i, filenames[i], tt.wantFilenames[i])
+				}
+			}
+			if got := isCompressedMappingRejected(); got != tt.wantRejected {
+				t.Errorf("isCompressedMappingRejected() = %v, want %v", got, tt.wantRejected)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var metadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// parseMetadata parses the comma separated `key=value` pairs.
+func parseMetadata(s string) (map[string]string, error) {
+	metadata := map[string]string{}
+	for _, pair := range splitCommaSeparatedList(s) {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid Metadata pair: %s, it should be in `key=value` format", pair)
+		}
+
+		key, value := strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		if !metadataKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid Metadata key: %q, it should contain only letters, digits and `_.-` characters", key)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("invalid Metadata pair: %s, the value is empty", pair)
+		}
+		if _, ok := metadata[key]; ok {
+			return nil, fmt.Errorf("invalid Metadata: duplicated key: %s", key)
+		}
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// metadataTags returns the metadata as `key=value` tags sorted by key,
+// as the HockeyApp upload API has no custom metadata field.
+func metadataTags(metadata map[string]string) []string {
+	keys := sortedKeys(metadata)
+	tags := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, key+"="+metadata[key])
+	}
+	return tags
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    map[string]string
+		wantErr bool
+	}{
+		{s: "", want: map[string]string{}},
+		{s: "branch=main, build.number = 42", want: map[string]string{"branch": "main", "build.number": "42"}},
+		{s: "query=a=b", want: map[string]string{"query": "a=b"}},
+		{s: "branch", wantErr: true},
+		{s: "branch=", wantErr: true},
+		{s: "git branch=main", wantErr: true},
+		{s: "branch=main,branch=dev", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := parseMetadata(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMetadata() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     []string
+	}{
+		{name: "empty", metadata: map[string]string{}, want: []string{}},
+		{name: "sorted by key", metadata: map[string]string{"sha": "abc", "branch": "main"}, want: []string{"branch=main", "sha=abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metadataTags(tt.metadata); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("metadataTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	metricsJobName     = "hockeyapp_android_deploy"
+	metricsPushTimeout = 10 * time.Second
+)
+
+var metricsLabelPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// parseMetricsLabels parses the comma separated `name=value` label pairs.
+func parseMetricsLabels(s string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, pair := range splitCommaSeparatedList(s) {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid MetricsLabels pair: %s, it should be in `name=value` format", pair)
+		}
+		name, value := strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		if !metricsLabelPattern.MatchString(name) || strings.HasPrefix(name, "__") || name == "job" {
+			return nil, fmt.Errorf("invalid MetricsLabels label name: %q", name)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("invalid MetricsLabels pair: %s, the value is empty", pair)
+		}
+		labels[name] = value
+	}
+	return labels, nil
+}
+
+// metricsPushURL returns the pushgateway URL of the step's job, grouped by the labels.
+func metricsPushURL(pushgatewayURL string, labels map[string]string) string {
+	u := strings.TrimSuffix(pushgatewayURL, "/") + "/metrics/job/" + metricsJobName
+	for _, name := range sortedKeys(labels) {
+		u += "/" + name + "/" + url.PathEscape(labels[name])
+	}
+	return u
+}
+
+// metricsPayload returns the metrics in the Prometheus text exposition format.
+func metricsPayload(success bool, duration time.Duration, artifactBytes uint64) string {
+	successValue := 0
+	if success {
+		successValue = 1
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# TYPE deploy_success gauge\ndeploy_success %d\n", successValue)
+	fmt.Fprintf(&b, "# TYPE deploy_duration_seconds gauge\ndeploy_duration_seconds %.3f\n", duration.Seconds())
+	fmt.Fprintf(&b, "# TYPE artifact_bytes gauge\nartifact_bytes %d\n", artifactBytes)
+	return b.String()
+}
+
+func artifactBytes() uint64 {
+	var total uint64
+	for _, artifact := range configs.artifacts() {
+		if size, err := fileSize(artifact.Path); err == nil {
+			total += size
+		}
+	}
+	return total
+}
+
+// pushMetrics pushes the deploy metrics to the MetricsPushgatewayURL,
+// failing to push only prints a warning.
+func pushMetrics(status StatusModel) {
+	if configs.MetricsPushgatewayURL == "" {
+		return
+	}
+
+	labels, err := parseMetricsLabels(configs.MetricsLabels)
+	if err != nil {
+		warnf("Failed to push metrics: %v", err)
+		return
+	}
+	payload := metricsPayload(status.Status == hockeyAppDeployStatusSuccess, time.Since(stepStartTime), artifactBytes())
+
+	if err := pushMetricsPayload(metricsPushURL(configs.MetricsPushgatewayURL, labels), payload); err != nil {
+		warnf("Failed to push metrics to: %s, error: %v", configs.MetricsPushgatewayURL, err)
+		return
+	}
+	printf("Metrics pushed to: %s", configs.MetricsPushgatewayURL)
+}
+
+func pushMetricsPayload(pushURL, payload string) error {
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metricsPushTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "PUT", pushURL, bytes.NewBufferString(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+	if _, err := io.Copy(ioutil.Discard, response.Body); err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return statusCodeError{StatusCode: response.StatusCode}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMetricsLabels(t *testing.T) {
+	tests := []struct {
+		labels  string
+		want    map[string]string
+		wantErr bool
+	}{
+		{labels: "", want: map[string]string{}},
+		{labels: "app=demo, branch = main", want: map[string]string{"app": "demo", "branch": "main"}},
+		{labels: "query=a=b", want: map[string]string{"query": "a=b"}},
+		{labels: "app", wantErr: true},
+		{labels: "app=", wantErr: true},
+		{labels: "1app=demo", wantErr: true},
+		{labels: "__name__=demo", wantErr: true},
+		{labels: "job=demo", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.labels, func(t *testing.T) {
+			got, err := parseMetricsLabels(tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMetricsLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMetricsLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsPushURL(t *testing.T) {
+	tests := []struct {
+		pushgatewayURL string
+		labels         map[string]string
+		want           string
+	}{
+		{pushgatewayURL: "https://push.example.com", want: "https://push.example.com/metrics/job/" + metricsJobName},
+		{pushgatewayURL: "https://push.example.com/", labels: map[string]string{"branch": "feature/x", "app": "demo"}, want: "https://push.example.com/metrics/job/" + metricsJobName + "/app/demo/branch/feature%2Fx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := metricsPushURL(tt.pushgatewayURL, tt.labels); got != tt.want {
+				t.Errorf("metricsPushURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		success bool
+		want    []string
+	}{
+		{name: "success", success: true, want: []string{"deploy_success 1\n", "deploy_duration_seconds 1.500\n", "artifact_bytes 1024\n"}},
+		{name: "failure", success: false, want: []string{"deploy_success 0\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := metricsPayload(tt.success, 1500*time.Millisecond, 1024)
+			for _, want := range tt.want {
+				if !strings.Contains(payload, want) {
+					t.Errorf("metricsPayload() = %q, want it to contain %q", payload, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPushMetricsPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "pushed", status: 202},
+		{name: "rejected", status: 400, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, body string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(b)
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			err := pushMetricsPayload(ts.URL+"/metrics/job/"+metricsJobName, "deploy_success 1\n")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pushMetricsPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if method != "PUT" || path != "/metrics/job/"+metricsJobName || body != "deploy_success 1\n" {
+				t.Errorf("request = %s %s %q, want the payload PUT to the job", method, path, body)
+			}
+		})
+	}
+}
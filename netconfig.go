@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NetworkConfigModel is the network_config_json input, the transport settings injected as a single JSON object.
+type NetworkConfigModel struct {
+	ProxyURL      string   `json:"proxy_url"`
+	NoProxy       []string `json:"no_proxy"`
+	CACertPath    string   `json:"ca_cert_path"`
+	TLSMinVersion string   `json:"tls_min_version"`
+}
+
+// tlsVersions maps the tls_min_version values to the TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// parseNetworkConfig parses the JSON object, unknown fields are rejected.
+func parseNetworkConfig(s string) (NetworkConfigModel, error) {
+	var networkConfig NetworkConfigModel
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&networkConfig); err != nil {
+		return NetworkConfigModel{}, fmt.Errorf("invalid NetworkConfigJSON, error: %v", err)
+	}
+	return networkConfig, nil
+}
+
+// applyNetworkConfig sets the settings of the NetworkConfigJSON not set by the individual inputs.
+func (configs *ConfigsModel) applyNetworkConfig() error {
+	if strings.TrimSpace(configs.NetworkConfigJSON) == "" {
+		return nil
+	}
+
+	networkConfig, err := parseNetworkConfig(configs.NetworkConfigJSON)
+	if err != nil {
+		return err
+	}
+	if configs.ProxyURL == "" {
+		configs.ProxyURL = networkConfig.ProxyURL
+	}
+	if len(configs.NoProxy) == 0 {
+		configs.NoProxy = networkConfig.NoProxy
+	}
+	if configs.CACertPath == "" {
+		configs.CACertPath = networkConfig.CACertPath
+	}
+	if configs.TLSMinVersion == "" {
+		configs.TLSMinVersion = networkConfig.TLSMinVersion
+	}
+	return nil
+}
+
+// isNoProxyHost reports whether the host matches any of the no proxy entries:
+// `*` matches every host, other entries match the host itself and its subdomains.
+func isNoProxyHost(host string, noProxy []string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(entry), "."))
+		if entry == "*" || host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyFunc returns the proxy of the transport, sending every request through the proxy URL
+// except the ones to the no proxy hosts.
+func proxyFunc(proxyURL *url.URL, noProxy []string) func(*http.Request) (*url.URL, error) {
+	return func(request *http.Request) (*url.URL, error) {
+		if isNoProxyHost(request.URL.Host, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// printableProxyURL returns the proxy URL with the password redacted.
+func printableProxyURL(proxyURL string) string {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.User == nil {
+		return proxyURL
+	}
+	if password, ok := u.User.Password(); ok && password != "" {
+		return redact(proxyURL, []string{password})
+	}
+	return proxyURL
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// netrcPassword returns the password of the machine in the netrc formatted file,
+// the default entry is used if the machine is not listed.
+func netrcPassword(pth, machine string) (string, bool, error) {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read credentials file at: %s, error: %v", pth, err)
+	}
+
+	var (
+		current         string
+		inEntry         bool
+		defaultPassword string
+		hasDefault      bool
+	)
+	passwords := map[string]string{}
+
+	lines := strings.Split(string(content), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for j := 0; j < len(fields); j++ {
+			switch fields[j] {
+			case "machine":
+				if j+1 >= len(fields) {
+					return "", false, fmt.Errorf("invalid credentials file: missing machine name on line %d", i+1)
+				}
+				j++
+				current, inEntry = fields[j], true
+			case "default":
+				current, inEntry = "", true
+				hasDefault = true
+			case "login", "account":
+				j++
+			case "password":
+				if j+1 >= len(fields) {
+					return "", false, fmt.Errorf("invalid credentials file: missing password on line %d", i+1)
+				}
+				j++
+				if !inEntry {
+					continue
+				}
+				if current == "" {
+					defaultPassword = fields[j]
+				} else {
+					passwords[current] = fields[j]
+				}
+			case "macdef":
+				// macro definitions last until the next empty line
+				for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+
+	if password, ok := passwords[machine]; ok {
+		return password, true, nil
+	}
+	if hasDefault && defaultPassword != "" {
+		return defaultPassword, true, nil
+	}
+	return "", false, nil
+}
+
+// apiHost returns the host of the API the step uploads to.
+func (configs ConfigsModel) apiHost() string {
+	apiURL := hockeyAppAPIURL
+	if configs.APIFlavor == apiFlavorAppCenter {
+		apiURL = appCenterAPIURL
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestNetrcPassword(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		pth := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(pth, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return pth
+	}
+	netrc := write("netrc", `# HockeyApp
+machine rink.hockeyapp.net login token password hockeyapp-token
+machine api.appcenter.ms
+  login token
+  password appcenter-token
+macdef init
+password macro-password
+
+default login anonymous password default-token
+`)
+	noDefault := write("netrc-no-default", "machine rink.hockeyapp.net password hockeyapp-token\n")
+	invalid := write("netrc-invalid", "machine rink.hockeyapp.net password\n")
+
+	tests := []struct {
+		name      string
+		pth       string
+		machine   string
+		want      string
+		wantFound bool
+		wantErr   bool
+	}{
+		{name: "single line entry", pth: netrc, machine: "rink.hockeyapp.net", want: "hockeyapp-token", wantFound: true},
+		{name: "multi line entry", pth: netrc, machine: "api.appcenter.ms", want: "appcenter-token", wantFound: true},
+		{name: "default entry", pth: netrc, machine: "example.com", want: "default-token", wantFound: true},
+		{name: "not found", pth: noDefault, machine: "example.com"},
+		{name: "missing password", pth: invalid, machine: "rink.hockeyapp.net", wantErr: true},
+		{name: "missing file", pth: filepath.Join(dir, "missing"), machine: "rink.hockeyapp.net", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := netrcPassword(tt.pth, tt.machine)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("netrcPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("netrcPassword() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestAPIHost(t *testing.T) {
+	tests := []struct {
+		apiFlavor string
+		want      string
+	}{
+		{apiFlavor: apiFlavorHockeyApp, want: "rink.hockeyapp.net"},
+		{apiFlavor: apiFlavorAppCenter, want: "api.appcenter.ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.apiFlavor, func(t *testing.T) {
+			if got := (ConfigsModel{APIFlavor: tt.apiFlavor}).apiHost(); got != tt.want {
+				t.Errorf("apiHost() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	notesTypeText     = "0"
+	notesTypeMarkdown = "1"
+)
+
+const (
+	notesOverflowModeFail     = "fail"
+	notesOverflowModeTruncate = "truncate"
+	notesOverflowModeWarn     = "warn"
+)
+
+// notesTruncationMarker is appended to the truncated notes.
+const notesTruncationMarker = "..."
+
+var markdownLinePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^#{1,6}\s+\S`),
+	regexp.MustCompile(`^[-*+]\s+\S`),
+	regexp.MustCompile(`^[0-9]+\.\s+\S`),
+	regexp.MustCompile("^```"),
+}
+
+var markdownLinkPattern = regexp.MustCompile(`\[[^\]]+\]\([^)\s]+\)`)
+
+// isMarkdownNotes reports whether the notes look like markdown: contain headings, list items, code fences or links.
+func isMarkdownNotes(notes string) bool {
+	if markdownLinkPattern.MatchString(notes) {
+		return true
+	}
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimSpace(line)
+		for _, pattern := range markdownLinePatterns {
+			if pattern.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detectNotesType returns the notes type matching the format of the notes.
+func detectNotesType(notes string) string {
+	if isMarkdownNotes(notes) {
+		return notesTypeMarkdown
+	}
+	return notesTypeText
+}
+
+// parseLocalizedNotes parses the JSON object of locale to notes.
+func parseLocalizedNotes(s string) (map[string]string, error) {
+	localizedNotes := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return localizedNotes, nil
+	}
+	if err := json.Unmarshal([]byte(s), &localizedNotes); err != nil {
+		return nil, fmt.Errorf("invalid LocalizedNotes, it should be a JSON object of locale to notes, error: %v", err)
+	}
+	for locale := range localizedNotes {
+		if strings.TrimSpace(locale) == "" {
+			return nil, errors.New("invalid LocalizedNotes, empty locale")
+		}
+	}
+	return localizedNotes, nil
+}
+
+// notesWithLocalizedNotes appends the localized notes to the notes, sorted by locale and each under a language header,
+// as the HockeyApp upload API has a single notes field.
+func notesWithLocalizedNotes(notes string, localizedNotes map[string]string, notesType string) string {
+	sections := []string{}
+	if notes != "" {
+		sections = append(sections, notes)
+	}
+	for _, locale := range sortedKeys(localizedNotes) {
+		header := fmt.Sprintf("[%s]", locale)
+		if notesType == notesTypeMarkdown {
+			header = "## " + locale
+		}
+		sections = append(sections, header+"\n"+strings.TrimSpace(localizedNotes[locale]))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// truncateNotes cuts the notes to at most maxLength characters (including the truncation marker),
+// at the last word boundary if there is any.
+func truncateNotes(notes string, maxLength int) string {
+	runes := []rune(notes)
+	if len(runes) <= maxLength {
+		return notes
+	}
+	limit := maxLength - utf8.RuneCountInString(notesTruncationMarker)
+	if limit <= 0 {
+		return string(runes[:maxLength])
+	}
+
+	cut := string(runes[:limit])
+	if !unicode.IsSpace(runes[limit]) {
+		if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRightFunc(cut, unicode.IsSpace) + notesTruncationMarker
+}
+
+// fitNotes handles the notes longer than maxLength characters based on the mode:
+// fails in fail mode (default), truncates them in truncate mode and only warns in warn mode.
+// The notes are not checked if maxLength is 0.
+func fitNotes(notes string, maxLength int, mode string) (string, error) {
+	length := utf8.RuneCountInString(notes)
+	if maxLength == 0 || length <= maxLength {
+		return notes, nil
+	}
+
+	switch mode {
+	case notesOverflowModeTruncate:
+		truncated := truncateNotes(notes, maxLength)
+		printf("Notes truncated from %d to %d characters", length, utf8.RuneCountInString(truncated))
+		return truncated, nil
+	case notesOverflowModeWarn:
+		warnf("Notes are longer (%d characters) than the allowed %d characters, sending them as is", length, maxLength)
+		return notes, nil
+	default:
+		return "", fmt.Errorf("notes are longer (%d characters) than the allowed %d characters", length, maxLength)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import "testing"
+
+func TestDetectNotesType(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{name: "plain text", notes: "Fixed the login crash.\nImproved the performance.", want: notesTypeText},
+		{name: "heading", notes: "## Changes\nFixed the login crash.", want: notesTypeMarkdown},
+		{name: "list", notes: "Changes:\n - Fixed the login crash", want: notesTypeMarkdown},
+		{name: "numbered list", notes: "1. Fixed the login crash", want: notesTypeMarkdown},
+		{name: "code fence", notes: "```\nlog\n```", want: notesTypeMarkdown},
+		{name: "link", notes: "See [the issue](https://example.com/1).", want: notesTypeMarkdown},
+		{name: "hash without heading", notes: "Fixed #123", want: notesTypeText},
+		{name: "dash without list", notes: "Version 1.2-beta", want: notesTypeText},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectNotesType(tt.notes); got != tt.want {
+				t.Errorf("detectNotesType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLocalizedNotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", s: " ", want: 0},
+		{name: "locales", s: `{"en": "Fixes", "de": "Korrekturen"}`, want: 2},
+		{name: "not an object", s: `["en"]`, wantErr: true},
+		{name: "empty locale", s: `{" ": "Fixes"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLocalizedNotes(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLocalizedNotes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("parseLocalizedNotes() = %v, want %d locales", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotesWithLocalizedNotes(t *testing.T) {
+	localizedNotes := map[string]string{"en": "Fixes\n", "de": "Korrekturen"}
+	tests := []struct {
+		name           string
+		notes          string
+		localizedNotes map[string]string
+		notesType      string
+		want           string
+	}{
+		{name: "no localized notes", notes: "Notes", want: "Notes"},
+		{name: "text", notes: "Notes", localizedNotes: localizedNotes, notesType: notesTypeText, want: "Notes\n\n[de]\nKorrekturen\n\n[en]\nFixes"},
+		{name: "markdown", localizedNotes: localizedNotes, notesType: notesTypeMarkdown, want: "## de\nKorrekturen\n\n## en\nFixes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notesWithLocalizedNotes(tt.notes, tt.localizedNotes, tt.notesType); got != tt.want {
+				t.Errorf("notesWithLocalizedNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateNotes(t *testing.T) {
+	tests := []struct {
+		name      string
+		notes     string
+		maxLength int
+		want      string
+	}{
+		{name: "short enough", notes: "Fixed the crash", maxLength: 15, want: "Fixed the crash"},
+		{name: "word boundary", notes: "Fixed the login crash", maxLength: 15, want: "Fixed the..."},
+		{name: "cut at a space", notes: "Fixed the login crash", maxLength: 13, want: "Fixed the..."},
+		{name: "no word boundary", notes: "Fixedthelogincrash", maxLength: 10, want: "Fixedth..."},
+		{name: "multibyte characters", notes: "árvíztűrő tükörfúrógép", maxLength: 12, want: "árvíztűrő..."},
+		{name: "shorter than the marker", notes: "Fixed the crash", maxLength: 2, want: "Fi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateNotes(tt.notes, tt.maxLength); got != tt.want {
+				t.Errorf("truncateNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitNotes(t *testing.T) {
+	notes := "Fixed the login crash"
+	tests := []struct {
+		name      string
+		maxLength int
+		mode      string
+		want      string
+		wantErr   bool
+	}{
+		{name: "not checked", maxLength: 0, want: notes},
+		{name: "fits", maxLength: 100, want: notes},
+		{name: "fail by default", maxLength: 10, wantErr: true},
+		{name: "fail", maxLength: 10, mode: notesOverflowModeFail, wantErr: true},
+		{name: "truncate", maxLength: 15, mode: notesOverflowModeTruncate, want: "Fixed the..."},
+		{name: "warn", maxLength: 10, mode: notesOverflowModeWarn, want: notes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fitNotes(notes, tt.maxLength, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fitNotes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fitNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
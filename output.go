@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/command"
+)
+
+const (
+	outputFormatEnvman = "envman"
+	outputFormatGithub = "github"
+	outputFormatDotenv = "dotenv"
+
+	githubEnvFileKey = "GITHUB_ENV"
+
+	envmanEnvstorePathKey = "ENVMAN_ENVSTORE_PATH"
+)
+
+// defaultExportFields are the response fields exported if ExportFields is empty.
+var defaultExportFields = []string{"public_url", "build_url", "config_url"}
+
+// responseFieldOutputKeys maps the response fields to the output keys derived from them.
+var responseFieldOutputKeys = map[string][]string{
+	"public_url": {hockeyAppDeployPublicURLKey, hockeyAppDeployPublicURLKeyList},
+	"build_url":  {hockeyAppDeployBuildURLKey, hockeyAppDeployBuildURLKeyList},
+	"config_url": {hockeyAppDeployConfigURLKey, hockeyAppDeployConfigURLKeyList},
+}
+
+// filterResponseOutputs returns the outputs without the ones derived from response fields not listed in exportFields,
+// unknown field names are ignored with a warning.
+func filterResponseOutputs(outputs map[string]string, exportFields []string) map[string]string {
+	enabled := map[string]bool{}
+	for _, field := range exportFields {
+		if _, ok := responseFieldOutputKeys[field]; !ok {
+			warnf("Unknown export field: %s, ignoring it", field)
+			continue
+		}
+		enabled[field] = true
+	}
+
+	filtered := map[string]string{}
+	for k, v := range outputs {
+		filtered[k] = v
+	}
+	for field, keys := range responseFieldOutputKeys {
+		if enabled[field] {
+			continue
+		}
+		for _, key := range keys {
+			delete(filtered, key)
+		}
+	}
+	return filtered
+}
+
+func exportEnvironmentWithEnvman(keyStr, valueStr string) error {
+	cmd := command.New("envman", "add", "--key", keyStr)
+	cmd.SetStdin(strings.NewReader(valueStr))
+	return cmd.Run()
+}
+
+// exportOutputs exports the outputs, the status output first: failing to export it returns an error,
+// while the other outputs are optional and failing to export them only prints a warning.
+// With envman, the outputs are written to the envstore at once if possible, instead of running envman for every output.
+func exportOutputs(outputs map[string]string) error {
+	if configs.OutputFormat == "" || configs.OutputFormat == outputFormatEnvman {
+		if pth := os.Getenv(envmanEnvstorePathKey); pth != "" {
+			err := appendToEnvstore(pth, outputs)
+			if err == nil {
+				return nil
+			}
+			printf("Failed to export the outputs at once (%v), exporting them one by one", err)
+		}
+	}
+
+	if status, ok := outputs[hockeyAppDeployStatusKey]; ok {
+		if err := exportOutput(hockeyAppDeployStatusKey, status); err != nil {
+			return fmt.Errorf("Failed to export %s, error: %v", hockeyAppDeployStatusKey, err)
+		}
+	}
+	for _, k := range sortedKeys(outputs) {
+		if k == hockeyAppDeployStatusKey {
+			continue
+		}
+		if err := exportOutput(k, outputs[k]); err != nil {
+			warnf("Failed to export %s, error: %v", k, err)
+		}
+	}
+	return nil
+}
+
+// envstoreItems returns the outputs as envstore list items, the status output first.
+// The values are JSON encoded, as JSON strings are valid YAML double quoted scalars.
+func envstoreItems(outputs map[string]string) (string, error) {
+	keys := []string{}
+	if _, ok := outputs[hockeyAppDeployStatusKey]; ok {
+		keys = append(keys, hockeyAppDeployStatusKey)
+	}
+	for _, k := range sortedKeys(outputs) {
+		if k != hockeyAppDeployStatusKey {
+			keys = append(keys, k)
+		}
+	}
+
+	var b strings.Builder
+	for _, k := range keys {
+		value, err := json.Marshal(outputs[k])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", outputKey(k), value)
+	}
+	return b.String(), nil
+}
+
+// appendToEnvstore appends the outputs to the envman envstore YAML file,
+// it fails if the envstore is not a plain `envs` list (as written by envman), so the outputs can be exported with envman instead.
+func appendToEnvstore(pth string, outputs map[string]string) error {
+	content, err := ioutil.ReadFile(pth)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	items, err := envstoreItems(outputs)
+	if err != nil {
+		return err
+	}
+
+	switch trimmed := strings.TrimSpace(string(content)); {
+	case trimmed == "" || trimmed == "{}" || trimmed == "envs: []":
+		return ioutil.WriteFile(pth, []byte("envs:\n"+items), 0644)
+	case isEnvsList(trimmed):
+		if !strings.HasSuffix(string(content), "\n") {
+			items = "\n" + items
+		}
+		return appendToFile(pth, items)
+	default:
+		return errors.New("unsupported envstore format")
+	}
+}
+
+// isEnvsList reports whether the envstore YAML contains only the `envs` list.
+func isEnvsList(envstore string) bool {
+	lines := strings.Split(envstore, "\n")
+	if lines[0] != "envs:" {
+		return false
+	}
+	for _, line := range lines[1:] {
+		if line != "" && !strings.HasPrefix(line, "- ") && !strings.HasPrefix(line, " ") {
+			return false
+		}
+	}
+	return true
+}
+
+func appendToFile(pth, content string) error {
+	f, err := os.OpenFile(pth, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		if cerr := f.Close(); cerr != nil {
+			return fmt.Errorf("%v, and failed to close file: %v", err, cerr)
+		}
+		return err
+	}
+	return f.Close()
+}
+
+// githubEnvLine formats the key-value pair for the $GITHUB_ENV file,
+// multiline values are written with the heredoc syntax.
+func githubEnvLine(key, value string) string {
+	if !strings.Contains(value, "\n") {
+		return fmt.Sprintf("%s=%s\n", key, value)
+	}
+	delimiter := "EOF_" + key
+	return fmt.Sprintf("%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+}
+
+// dotenvLine formats the key-value pair for a .env file,
+// values containing whitespace or quotes are double quoted.
+func dotenvLine(key, value string) string {
+	if strings.ContainsAny(value, " \t\n\"'#") {
+		value = strconv.Quote(value)
+	}
+	return fmt.Sprintf("%s=%s\n", key, value)
+}
+
+var outputKeyPrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// outputKey returns the exported key of the output, prefixed with the OutputKeyPrefix.
+func outputKey(key string) string {
+	return configs.OutputKeyPrefix + key
+}
+
+func exportOutput(key, value string) error {
+	key = outputKey(key)
+	switch configs.OutputFormat {
+	case outputFormatGithub:
+		pth := os.Getenv(githubEnvFileKey)
+		if pth == "" {
+			return fmt.Errorf("%s is not set", githubEnvFileKey)
+		}
+		return appendToFile(pth, githubEnvLine(key, value))
+	case outputFormatDotenv:
+		return appendToFile(configs.DotenvPath, dotenvLine(key, value))
+	default:
+		return exportEnvironmentWithEnvman(key, value)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGithubEnvLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{name: "single line", key: "URL", value: "https://install", want: "URL=https://install\n"},
+		{name: "empty", key: "URL", value: "", want: "URL=\n"},
+		{name: "multiline", key: "NOTES", value: "line 1\nline 2", want: "NOTES<<EOF_NOTES\nline 1\nline 2\nEOF_NOTES\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := githubEnvLine(tt.key, tt.value); got != tt.want {
+				t.Errorf("githubEnvLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotenvLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "https://install", want: "KEY=https://install\n"},
+		{name: "whitespace", value: "release notes", want: "KEY=\"release notes\"\n"},
+		{name: "multiline", value: "line 1\nline 2", want: "KEY=\"line 1\\nline 2\"\n"},
+		{name: "quotes", value: `say "hi"`, want: "KEY=\"say \\\"hi\\\"\"\n"},
+		{name: "comment", value: "a#b", want: "KEY=\"a#b\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dotenvLine("KEY", tt.value); got != tt.want {
+				t.Errorf("dotenvLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportOutput(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputFormat string
+		githubEnv    bool
+		want         string
+		wantErr      bool
+	}{
+		{name: "github", outputFormat: outputFormatGithub, githubEnv: true, want: "HOCKEYAPP_DEPLOY_STATUS=success\n"},
+		{name: "github without GITHUB_ENV", outputFormat: outputFormatGithub, wantErr: true},
+		{name: "dotenv", outputFormat: outputFormatDotenv, want: "HOCKEYAPP_DEPLOY_STATUS=success\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pth := filepath.Join(t.TempDir(), "outputs")
+			t.Setenv(githubEnvFileKey, "")
+			if tt.githubEnv {
+				t.Setenv(githubEnvFileKey, pth)
+			}
+			setConfigs(t, ConfigsModel{OutputFormat: tt.outputFormat, DotenvPath: pth})
+
+			err := exportOutput(hockeyAppDeployStatusKey, hockeyAppDeployStatusSuccess)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("exportOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if content, err := ioutil.ReadFile(pth); err != nil || string(content) != tt.want {
+				t.Errorf("exported = %q (error: %v), want %q", content, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterResponseOutputs(t *testing.T) {
+	outputs := map[string]string{
+		hockeyAppDeployStatusKey:        hockeyAppDeployStatusSuccess,
+		hockeyAppDeployPublicURLKey:     "https://install",
+		hockeyAppDeployPublicURLKeyList: "https://install",
+		hockeyAppDeployBuildURLKey:      "https://download",
+		hockeyAppDeployBuildURLKeyList:  "https://download",
+		hockeyAppDeployConfigURLKey:     "https://config",
+		hockeyAppDeployConfigURLKeyList: "https://config",
+	}
+	tests := []struct {
+		name         string
+		exportFields []string
+		wantKeys     []string
+	}{
+		{name: "default fields", exportFields: defaultExportFields, wantKeys: sortedKeys(outputs)},
+		{name: "public URL only", exportFields: []string{"public_url"}, wantKeys: sortedKeys(map[string]string{hockeyAppDeployStatusKey: "", hockeyAppDeployPublicURLKey: "", hockeyAppDeployPublicURLKeyList: ""})},
+		{name: "unknown field ignored", exportFields: []string{"build_url", "download_url"}, wantKeys: sortedKeys(map[string]string{hockeyAppDeployStatusKey: "", hockeyAppDeployBuildURLKey: "", hockeyAppDeployBuildURLKeyList: ""})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterResponseOutputs(outputs, tt.exportFields)
+			if !reflect.DeepEqual(sortedKeys(got), tt.wantKeys) {
+				t.Errorf("filterResponseOutputs() keys = %v, want %v", sortedKeys(got), tt.wantKeys)
+			}
+		})
+	}
+	if len(outputs) != 7 {
+		t.Errorf("filterResponseOutputs() modified the outputs: %v", outputs)
+	}
+}
+
+func TestOutputKey(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{prefix: "", want: hockeyAppDeployStatusKey},
+		{prefix: "STAGING_", want: "STAGING_" + hockeyAppDeployStatusKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{OutputKeyPrefix: tt.prefix})
+			if got := outputKey(hockeyAppDeployStatusKey); got != tt.want {
+				t.Errorf("outputKey() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportOutputsStatusFirst(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), ".env")
+	setConfigs(t, ConfigsModel{OutputFormat: outputFormatDotenv, DotenvPath: pth})
+
+	if err := exportOutputs(map[string]string{
+		hockeyAppDeployBuildURLKey:  "https://download",
+		hockeyAppDeployStatusKey:    hockeyAppDeployStatusSuccess,
+		hockeyAppDeployPublicURLKey: "https://install",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "HOCKEYAPP_DEPLOY_STATUS=success\nHOCKEYAPP_DEPLOY_BUILD_URL=https://download\nHOCKEYAPP_DEPLOY_PUBLIC_URL=https://install\n"
+	if string(content) != want {
+		t.Errorf("exported = %q, want %q", content, want)
+	}
+}
+
+func TestAppendToEnvstore(t *testing.T) {
+	outputs := map[string]string{
+		hockeyAppDeployPublicURLKey: "https://install",
+		hockeyAppDeployStatusKey:    hockeyAppDeployStatusSuccess,
+	}
+	items := "- HOCKEYAPP_DEPLOY_STATUS: \"success\"\n- HOCKEYAPP_DEPLOY_PUBLIC_URL: \"https://install\"\n"
+	tests := []struct {
+		name     string
+		envstore *string
+		want     string
+		wantErr  bool
+	}{
+		{name: "missing envstore", want: "envs:\n" + items},
+		{name: "empty envstore", envstore: stringPtr("{}\n"), want: "envs:\n" + items},
+		{name: "empty list", envstore: stringPtr("envs: []\n"), want: "envs:\n" + items},
+		{name: "envs list", envstore: stringPtr("envs:\n- BITRISE_APK_PATH: app.apk\n"), want: "envs:\n- BITRISE_APK_PATH: app.apk\n" + items},
+		{name: "envs list without trailing newline", envstore: stringPtr("envs:\n- BITRISE_APK_PATH: |-\n    app.apk"), want: "envs:\n- BITRISE_APK_PATH: |-\n    app.apk\n" + items},
+		{name: "unsupported format", envstore: stringPtr("envs:\n- A: b\nother: value\n"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{})
+			pth := filepath.Join(t.TempDir(), "envstore.yml")
+			if tt.envstore != nil {
+				if err := ioutil.WriteFile(pth, []byte(*tt.envstore), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := appendToEnvstore(pth, outputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("appendToEnvstore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if content, err := ioutil.ReadFile(pth); err != nil || string(content) != tt.want {
+				t.Errorf("envstore = %q (error: %v), want %q", content, err, tt.want)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	outputMetadataSelectionAll       = "all"
+	outputMetadataSelectionUniversal = "universal"
+)
+
+// OutputMetadataModel is the output-metadata.json written by the Android Gradle Plugin next to the built APKs.
+type OutputMetadataModel struct {
+	Version       int                          `json:"version"`
+	ArtifactType  OutputMetadataArtifactModel  `json:"artifactType"`
+	ApplicationID string                       `json:"applicationId"`
+	VariantName   string                       `json:"variantName"`
+	Elements      []OutputMetadataElementModel `json:"elements"`
+}
+
+// OutputMetadataArtifactModel ...
+type OutputMetadataArtifactModel struct {
+	Type string `json:"type"`
+}
+
+// OutputMetadataElementModel is a built APK, split APKs have ABI or density filters.
+type OutputMetadataElementModel struct {
+	Type        string                      `json:"type"`
+	Filters     []OutputMetadataFilterModel `json:"filters"`
+	VersionCode int                         `json:"versionCode"`
+	VersionName string                      `json:"versionName"`
+	OutputFile  string                      `json:"outputFile"`
+}
+
+// OutputMetadataFilterModel ...
+type OutputMetadataFilterModel struct {
+	FilterType string `json:"filterType"`
+	Value      string `json:"value"`
+}
+
+func (element OutputMetadataElementModel) isUniversal() bool {
+	return element.Type == "SINGLE" || element.Type == "UNIVERSAL" || len(element.Filters) == 0
+}
+
+func parseOutputMetadata(data []byte) (OutputMetadataModel, error) {
+	var metadata OutputMetadataModel
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return OutputMetadataModel{}, fmt.Errorf("invalid output metadata JSON, error: %v", err)
+	}
+	if metadata.ArtifactType.Type != "APK" {
+		return OutputMetadataModel{}, fmt.Errorf("invalid output metadata artifact type: %q, only APK outputs are supported", metadata.ArtifactType.Type)
+	}
+	if len(metadata.Elements) == 0 {
+		return OutputMetadataModel{}, fmt.Errorf("no elements found in the output metadata")
+	}
+	for _, element := range metadata.Elements {
+		if element.OutputFile == "" {
+			return OutputMetadataModel{}, fmt.Errorf("invalid output metadata element: no outputFile specified")
+		}
+	}
+	return metadata, nil
+}
+
+// selectOutputMetadataElements returns the elements matching the selection:
+// `all` returns every element, `universal` the ones without ABI and density filters.
+func selectOutputMetadataElements(elements []OutputMetadataElementModel, selection string) ([]OutputMetadataElementModel, error) {
+	if selection == "" || selection == outputMetadataSelectionAll {
+		return elements, nil
+	}
+
+	var selected []OutputMetadataElementModel
+	for _, element := range elements {
+		if element.isUniversal() {
+			selected = append(selected, element)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no universal APK found in the output metadata")
+	}
+	return selected, nil
+}
+
+// readOutputMetadata returns the paths of the selected APKs (relative to the metadata file's directory)
+// and the version info of the first one.
+func readOutputMetadata(pth, selection string) ([]string, ManifestModel, error) {
+	data, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return nil, ManifestModel{}, fmt.Errorf("failed to read output metadata at: %s, error: %v", pth, err)
+	}
+	metadata, err := parseOutputMetadata(data)
+	if err != nil {
+		return nil, ManifestModel{}, err
+	}
+	elements, err := selectOutputMetadataElements(metadata.Elements, selection)
+	if err != nil {
+		return nil, ManifestModel{}, err
+	}
+
+	var apkPaths []string
+	for _, element := range elements {
+		apkPaths = append(apkPaths, resolvePath(filepath.Dir(pth), element.OutputFile))
+	}
+	manifest := ManifestModel{
+		PackageName: metadata.ApplicationID,
+		VersionCode: strconv.Itoa(elements[0].VersionCode),
+		VersionName: elements[0].VersionName,
+	}
+	return apkPaths, manifest, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseOutputMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantElements int
+		wantErr      bool
+	}{
+		{name: "APK elements", data: `{"artifactType": {"type": "APK"}, "elements": [{"type": "SINGLE", "outputFile": "app.apk"}]}`, wantElements: 1},
+		{name: "invalid JSON", data: `{"artifactType": `, wantErr: true},
+		{name: "bundle output", data: `{"artifactType": {"type": "BUNDLE"}, "elements": [{"outputFile": "app.aab"}]}`, wantErr: true},
+		{name: "no elements", data: `{"artifactType": {"type": "APK"}, "elements": []}`, wantErr: true},
+		{name: "no output file", data: `{"artifactType": {"type": "APK"}, "elements": [{"type": "SINGLE"}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := parseOutputMetadata([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutputMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(metadata.Elements) != tt.wantElements {
+				t.Errorf("parseOutputMetadata() = %d elements, want %d", len(metadata.Elements), tt.wantElements)
+			}
+		})
+	}
+}
+
+func TestSelectOutputMetadataElements(t *testing.T) {
+	split := OutputMetadataElementModel{Type: "ONE_OF_MANY", Filters: []OutputMetadataFilterModel{{FilterType: "ABI", Value: "x86"}}, OutputFile: "app-x86.apk"}
+	universal := OutputMetadataElementModel{Type: "UNIVERSAL", OutputFile: "app-universal.apk"}
+	tests := []struct {
+		name      string
+		elements  []OutputMetadataElementModel
+		selection string
+		want      []OutputMetadataElementModel
+		wantErr   bool
+	}{
+		{name: "default selection", elements: []OutputMetadataElementModel{split, universal}, want: []OutputMetadataElementModel{split, universal}},
+		{name: "all", elements: []OutputMetadataElementModel{split, universal}, selection: outputMetadataSelectionAll, want: []OutputMetadataElementModel{split, universal}},
+		{name: "universal", elements: []OutputMetadataElementModel{split, universal}, selection: outputMetadataSelectionUniversal, want: []OutputMetadataElementModel{universal}},
+		{name: "no universal", elements: []OutputMetadataElementModel{split}, selection: outputMetadataSelectionUniversal, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectOutputMetadataElements(tt.elements, tt.selection)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectOutputMetadataElements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectOutputMetadataElements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOutputMetadata(t *testing.T) {
+	wantManifest := ManifestModel{PackageName: "com.example.app", VersionCode: "42", VersionName: "1.2.3"}
+	tests := []struct {
+		name         string
+		pth          string
+		selection    string
+		wantPaths    []string
+		wantManifest ManifestModel
+		wantErr      bool
+	}{
+		{name: "all", pth: "testdata/output-metadata.json", selection: outputMetadataSelectionAll, wantPaths: []string{filepath.Join("testdata", "app-arm64-v8a-release.apk"), filepath.Join("testdata", "app-universal-release.apk")}, wantManifest: wantManifest},
+		{name: "universal", pth: "testdata/output-metadata.json", selection: outputMetadataSelectionUniversal, wantPaths: []string{filepath.Join("testdata", "app-universal-release.apk")}, wantManifest: wantManifest},
+		{name: "missing", pth: "testdata/missing.json", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, manifest, err := readOutputMetadata(tt.pth, tt.selection)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readOutputMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("readOutputMetadata() paths = %v, want %v", paths, tt.wantPaths)
+			}
+			if manifest != tt.wantManifest {
+				t.Errorf("readOutputMetadata() manifest = %+v, want %+v", manifest, tt.wantManifest)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// requestPackageFileName is the name of the metadata sidecar in the package directory.
+const requestPackageFileName = "package.json"
+
+// PackagedRequestModel is an upload request written to disk, its body is stored in the BodyFile next to the sidecar.
+type PackagedRequestModel struct {
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers"`
+	BodyFile       string            `json:"body_file"`
+	IdempotencyKey string            `json:"idempotency_key"`
+	Checksums      map[string]string `json:"checksums"`
+	Artifact       ArtifactModel     `json:"artifact"`
+}
+
+// RequestPackageModel is the metadata sidecar of the packaged upload requests, the API token is not included.
+type RequestPackageModel struct {
+	Requests []PackagedRequestModel `json:"requests"`
+}
+
+// packageRequest writes the multipart body of the request to the package directory
+// and returns its metadata, the headers set on every attempt (API token, signature) are not included.
+func packageRequest(dir string, index int, method, requestURL string, fields, files map[string]string, artifact ArtifactModel, idempotencyKey string) (PackagedRequestModel, error) {
+	request, checksums, err := createRequest(method, requestURL, fields, files, nil)
+	if err != nil {
+		return PackagedRequestModel{}, fmt.Errorf("failed to create request, error: %v", err)
+	}
+	setExtraHeaders(request)
+
+	bodyFile := fmt.Sprintf("request-%d.body", index)
+	f, err := os.Create(filepath.Join(dir, bodyFile))
+	if err != nil {
+		return PackagedRequestModel{}, err
+	}
+	_, err = io.Copy(f, request.Body)
+	if cerr := f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return PackagedRequestModel{}, fmt.Errorf("failed to write request body, error: %v", err)
+	}
+
+	headers := map[string]string{}
+	for name := range request.Header {
+		headers[name] = request.Header.Get(name)
+	}
+	return PackagedRequestModel{
+		Method:         method,
+		URL:            requestURL,
+		Headers:        headers,
+		BodyFile:       bodyFile,
+		IdempotencyKey: idempotencyKey,
+		Checksums:      checksums,
+		Artifact:       artifact,
+	}, nil
+}
+
+// writeRequestPackage writes the metadata sidecar of the packaged requests to the package directory.
+func writeRequestPackage(dir string, requestPackage RequestPackageModel) error {
+	data, err := json.MarshalIndent(requestPackage, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, requestPackageFileName), data, 0644)
+}
+
+// readRequestPackage reads the metadata sidecar of the packaged requests from the package directory.
+func readRequestPackage(dir string) (RequestPackageModel, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, requestPackageFileName))
+	if err != nil {
+		return RequestPackageModel{}, fmt.Errorf("failed to read the request package, error: %v", err)
+	}
+	var requestPackage RequestPackageModel
+	if err := json.Unmarshal(data, &requestPackage); err != nil {
+		return RequestPackageModel{}, fmt.Errorf("failed to parse the request package, error: %v", err)
+	}
+	if len(requestPackage.Requests) == 0 {
+		return RequestPackageModel{}, fmt.Errorf("no requests found in the request package: %s", dir)
+	}
+	return requestPackage, nil
+}
+
+// packagedRequest returns the builder of the request replaying the packaged request.
+func packagedRequest(dir string, packaged PackagedRequestModel, reporter ProgressReporter) requestBuilder {
+	return func() (*http.Request, map[string]string, error) {
+		body, err := ioutil.ReadFile(filepath.Join(dir, packaged.BodyFile))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var reader io.Reader = bytes.NewReader(body)
+		if reporter != nil {
+			reader = &progressReader{reader: reader, total: int64(len(body)), reporter: reporter}
+		}
+		request, err := http.NewRequest(packaged.Method, packaged.URL, reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		request.ContentLength = int64(len(body))
+		for name, value := range packaged.Headers {
+			request.Header.Set(name, value)
+		}
+		return request, packaged.Checksums, nil
+	}
+}
+
+// deployPackagedRequest uploads the packaged request with the API token and the signature of the current step run.
+func deployPackagedRequest(ctx context.Context, packaged PackagedRequestModel, reporter ProgressReporter) (ResponseModel, error) {
+	printNewline()
+	infof("Performing packaged request (%s: %s)", packaged.Artifact.Type, packaged.Artifact.Path)
+
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+	return performRequestWithRetry(ctx, client, packagedRequest(configs.UploadFromPackage, packaged, reporter), packaged.Artifact, packaged.IdempotencyKey, reporter)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRequestPackageRoundTrip(t *testing.T) {
+	setConfigs(t, ConfigsModel{extraHeaders: http.Header{"X-Trace": {"abc"}}})
+	dir := t.TempDir()
+	artifact := ArtifactModel{Type: artifactTypeAPK, Path: "testdata/app.apk"}
+
+	packaged, err := packageRequest(dir, 0, "POST", "https://rink.hockeyapp.net/api/2/apps/upload", map[string]string{"notes": "notes"}, map[string]string{"ipa": artifact.Path}, artifact, "key")
+	if err != nil {
+		t.Fatalf("packageRequest() error = %v", err)
+	}
+	if packaged.BodyFile != "request-0.body" || packaged.Headers["X-Trace"] != "abc" || packaged.Headers["Content-Type"] == "" {
+		t.Errorf("packageRequest() = %+v, want the body file and the request headers", packaged)
+	}
+	if _, ok := packaged.Headers["X-Hockeyapptoken"]; ok {
+		t.Errorf("packageRequest() headers = %v, want no API token", packaged.Headers)
+	}
+
+	if err := writeRequestPackage(dir, RequestPackageModel{Requests: []PackagedRequestModel{packaged}}); err != nil {
+		t.Fatalf("writeRequestPackage() error = %v", err)
+	}
+	requestPackage, err := readRequestPackage(dir)
+	if err != nil {
+		t.Fatalf("readRequestPackage() error = %v", err)
+	}
+	if !reflect.DeepEqual(requestPackage.Requests, []PackagedRequestModel{packaged}) {
+		t.Errorf("readRequestPackage() = %+v, want %+v", requestPackage.Requests, packaged)
+	}
+
+	request, checksums, err := packagedRequest(dir, requestPackage.Requests[0], nil)()
+	if err != nil {
+		t.Fatalf("packagedRequest() error = %v", err)
+	}
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := ioutil.ReadFile(filepath.Join(dir, packaged.BodyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != string(stored) || request.ContentLength != int64(len(stored)) {
+		t.Errorf("packaged request body differs from the stored body")
+	}
+	if request.Header.Get("Content-Type") != packaged.Headers["Content-Type"] || !reflect.DeepEqual(checksums, packaged.Checksums) {
+		t.Errorf("packaged request = %v, %v, want the packaged headers and checksums", request.Header, checksums)
+	}
+}
+
+func TestReadRequestPackage(t *testing.T) {
+	tests := []struct {
+		name    string
+		sidecar string
+		wantErr bool
+	}{
+		{name: "requests", sidecar: `{"requests": [{"method": "POST", "body_file": "request-0.body"}]}`},
+		{name: "no requests", sidecar: `{"requests": []}`, wantErr: true},
+		{name: "invalid JSON", sidecar: `{"requests": `, wantErr: true},
+		{name: "missing sidecar", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.sidecar != "" {
+				if err := ioutil.WriteFile(filepath.Join(dir, requestPackageFileName), []byte(tt.sidecar), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := readRequestPackage(dir); (err != nil) != tt.wantErr {
+				t.Errorf("readRequestPackage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
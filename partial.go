@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bitrise-io/go-utils/log"
+)
+
+const (
+	partialFailureModeFail = "fail"
+	partialFailureModeWarn = "warn"
+)
+
+// DeployResultModel is the outcome of the deploy of a single artifact.
+type DeployResultModel struct {
+	Artifact ArtifactModel
+	Err      error
+}
+
+// Status ...
+func (r DeployResultModel) Status() string {
+	if r.Err != nil {
+		return hockeyAppDeployStatusFailed
+	}
+	return hockeyAppDeployStatusSuccess
+}
+
+func failedDeployCount(results []DeployResultModel) int {
+	count := 0
+	for _, result := range results {
+		if result.Err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// deployStatusMap returns the per artifact statuses in `path=status` format, separated with `|`.
+func deployStatusMap(results []DeployResultModel) string {
+	statuses := make([]string, 0, len(results))
+	for _, result := range results {
+		statuses = append(statuses, fmt.Sprintf("%s=%s", result.Artifact.Path, result.Status()))
+	}
+	return strings.Join(statuses, "|")
+}
+
+// isPartialDeploy reports whether some, but not all of the deploys failed.
+func isPartialDeploy(results []DeployResultModel) bool {
+	failed := failedDeployCount(results)
+	return failed > 0 && failed < len(results)
+}
+
+func printDeployBreakdown(results []DeployResultModel) {
+	printNewline()
+	infof("Deploy results:")
+	for _, result := range results {
+		if result.Err != nil {
+			log.Errorf(" - %s: %s (%v)", result.Artifact.Path, result.Status(), result.Err)
+		} else {
+			donef(" - %s: %s", result.Artifact.Path, result.Status())
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDeployResults(t *testing.T) {
+	apk := ArtifactModel{Type: artifactTypeAPK, Path: "app.apk"}
+	aab := ArtifactModel{Type: "aab", Path: "app.aab"}
+	errUpload := errors.New("upload failed")
+	tests := []struct {
+		name        string
+		results     []DeployResultModel
+		wantFailed  int
+		wantPartial bool
+		wantStatus  string
+	}{
+		{name: "succeeded", results: []DeployResultModel{{Artifact: apk}, {Artifact: aab}}, wantStatus: "app.apk=success|app.aab=success"},
+		{name: "partial", results: []DeployResultModel{{Artifact: apk}, {Artifact: aab, Err: errUpload}}, wantFailed: 1, wantPartial: true, wantStatus: "app.apk=success|app.aab=failed"},
+		{name: "failed", results: []DeployResultModel{{Artifact: apk, Err: errUpload}, {Artifact: aab, Err: errUpload}}, wantFailed: 2, wantStatus: "app.apk=failed|app.aab=failed"},
+		{name: "no results", results: nil, wantStatus: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := failedDeployCount(tt.results); got != tt.wantFailed {
+				t.Errorf("failedDeployCount() = %d, want %d", got, tt.wantFailed)
+			}
+			if got := isPartialDeploy(tt.results); got != tt.wantPartial {
+				t.Errorf("isPartialDeploy() = %v, want %v", got, tt.wantPartial)
+			}
+			if got := deployStatusMap(tt.results); got != tt.wantStatus {
+				t.Errorf("deployStatusMap() = %q, want %q", got, tt.wantStatus)
+			}
+		})
+	}
+}
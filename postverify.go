@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// checkUploadedVersion compares the version reported by the server with the uploaded artifact:
+// its size with the size of the APK or AAB, and its versions with the manifest of the APK, if available.
+func checkUploadedVersion(version AppVersionModel, artifact ArtifactModel, manifest *ManifestModel) error {
+	var mismatches []string
+	if artifact.Type == artifactTypeAPK || artifact.Type == artifactTypeAAB {
+		size, err := fileSize(artifact.Path)
+		if err != nil {
+			return fmt.Errorf("failed to get the size of %s, error: %v", artifact.Path, err)
+		}
+		if version.AppSize != int64(size) {
+			mismatches = append(mismatches, fmt.Sprintf("size: %d bytes reported, %d bytes uploaded", version.AppSize, size))
+		}
+	}
+	if manifest != nil {
+		if manifest.VersionCode != "" && version.Version != manifest.VersionCode {
+			mismatches = append(mismatches, fmt.Sprintf("version: %s reported, %s uploaded", version.Version, manifest.VersionCode))
+		}
+		if manifest.VersionName != "" && version.ShortVersion != manifest.VersionName {
+			mismatches = append(mismatches, fmt.Sprintf("short version: %s reported, %s uploaded", version.ShortVersion, manifest.VersionName))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("version %d does not match the uploaded %s: %s", version.ID, artifact.Path, strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+// verifyUploadedVersion fetches the metadata of the uploaded version and checks it against the artifact.
+func verifyUploadedVersion(ctx context.Context, versionID int, artifact ArtifactModel) error {
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+	versions, err := fetchAppVersions(ctx, client, configs.AppID)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch app versions, error: %v", err)
+	}
+
+	var manifest *ManifestModel
+	if artifact.Type == artifactTypeAPK {
+		if m, err := readAPKManifest(artifact.Path); err != nil {
+			warnf("Failed to read the manifest, the versions are not verified: %v", err)
+		} else {
+			manifest = &m
+		}
+	}
+
+	for _, version := range versions {
+		if version.ID == versionID {
+			return checkUploadedVersion(version, artifact, manifest)
+		}
+	}
+	return fmt.Errorf("uploaded version %d not found in the app versions", versionID)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckUploadedVersion(t *testing.T) {
+	size, err := fileSize("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	apk := ArtifactModel{Type: artifactTypeAPK, Path: "testdata/app.apk"}
+	manifest := &ManifestModel{VersionCode: "42", VersionName: "1.2.3"}
+	tests := []struct {
+		name     string
+		version  AppVersionModel
+		artifact ArtifactModel
+		manifest *ManifestModel
+		wantErr  bool
+	}{
+		{name: "match", version: AppVersionModel{AppSize: int64(size), Version: "42", ShortVersion: "1.2.3"}, artifact: apk, manifest: manifest},
+		{name: "size only", version: AppVersionModel{AppSize: int64(size)}, artifact: apk},
+		{name: "size mismatch", version: AppVersionModel{AppSize: 1, Version: "42", ShortVersion: "1.2.3"}, artifact: apk, manifest: manifest, wantErr: true},
+		{name: "version mismatch", version: AppVersionModel{AppSize: int64(size), Version: "41", ShortVersion: "1.2.3"}, artifact: apk, manifest: manifest, wantErr: true},
+		{name: "short version mismatch", version: AppVersionModel{AppSize: int64(size), Version: "42", ShortVersion: "1.2.2"}, artifact: apk, manifest: manifest, wantErr: true},
+		{name: "mapping size not checked", version: AppVersionModel{AppSize: 1}, artifact: ArtifactModel{Type: artifactTypeMapping, Path: "testdata/output-metadata.json"}},
+		{name: "missing artifact", version: AppVersionModel{}, artifact: ArtifactModel{Type: artifactTypeAPK, Path: "testdata/missing.apk"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkUploadedVersion(tt.version, tt.artifact, tt.manifest); (err != nil) != tt.wantErr {
+				t.Errorf("checkUploadedVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyUploadedVersion(t *testing.T) {
+	size, err := fileSize("testdata/app.apk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		versionID int
+		versions  []AppVersionModel
+		wantErr   bool
+	}{
+		{name: "verified", versionID: 7, versions: []AppVersionModel{{ID: 7, Version: "42", ShortVersion: "1.2.3", AppSize: int64(size)}}},
+		{name: "manifest mismatch", versionID: 7, versions: []AppVersionModel{{ID: 7, Version: "41", ShortVersion: "1.2.3", AppSize: int64(size)}}, wantErr: true},
+		{name: "version not found", versionID: 8, versions: []AppVersionModel{{ID: 7}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{AppID: "app-id", APIToken: "token"})
+			newHockeyAppServer(t, tt.versions)
+
+			err := verifyUploadedVersion(context.Background(), tt.versionID, ArtifactModel{Type: artifactTypeAPK, Path: "testdata/app.apk"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyUploadedVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
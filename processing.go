@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	processingPollInterval = 10 * time.Second
+
+	processingStateReady   = "ready"
+	processingStateTimeout = "timeout"
+)
+
+// isAppVersionProcessed reports whether the version is listed with its binary processed.
+func isAppVersionProcessed(versions []AppVersionModel, id int) bool {
+	for _, v := range versions {
+		if v.ID == id {
+			return v.AppSize > 0
+		}
+	}
+	return false
+}
+
+// waitForProcessing polls the app versions until the uploaded version is processed or the timeout elapses,
+// it returns the final processing state.
+func waitForProcessing(ctx context.Context, versionID int, timeout time.Duration) (string, error) {
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		versions, err := fetchAppVersions(ctx, client, configs.AppID)
+		if err != nil && ctx.Err() == nil {
+			return "", err
+		}
+		if isAppVersionProcessed(versions, versionID) {
+			return processingStateReady, nil
+		}
+
+		printf("Version %d is being processed, checking again in %s...", versionID, processingPollInterval)
+		select {
+		case <-time.After(processingPollInterval):
+		case <-ctx.Done():
+			return processingStateTimeout, nil
+		}
+	}
+}
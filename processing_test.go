@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestIsAppVersionProcessed(t *testing.T) {
+	versions := []AppVersionModel{{ID: 8, AppSize: 0}, {ID: 7, AppSize: 1024}}
+	tests := []struct {
+		name string
+		id   int
+		want bool
+	}{
+		{name: "processed", id: 7, want: true},
+		{name: "being processed", id: 8, want: false},
+		{name: "not listed", id: 9, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAppVersionProcessed(versions, tt.id); got != tt.want {
+				t.Errorf("isAppVersionProcessed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForProcessing(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []AppVersionModel
+		want     string
+	}{
+		{name: "ready", versions: []AppVersionModel{{ID: 7, AppSize: 1024}}, want: processingStateReady},
+		{name: "timeout", versions: []AppVersionModel{{ID: 7}}, want: processingStateTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{AppID: "app-id", APIToken: "token"})
+			newHockeyAppServer(t, tt.versions)
+
+			got, err := waitForProcessing(context.Background(), 7, 50*time.Millisecond)
+			if err != nil {
+				t.Fatalf("waitForProcessing() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("waitForProcessing() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	profileKey  = "profile"
+	profilesKey = "profiles"
+)
+
+// inputDefaults are the non-empty input defaults declared in the step.yml,
+// Bitrise always sets them, so they can not tell apart an unset input.
+var inputDefaults = map[string]string{
+	"apk_path":                  "$BITRISE_APK_PATH",
+	"notes":                     "Deploy with Bitrise HockeyApp Deploy Step.",
+	"notify":                    "2",
+	"status":                    "2",
+	"mandatory":                 "false",
+	"auto_tag_build_number":     "false",
+	"build_number_env":          "BITRISE_BUILD_NUMBER",
+	"print_summary":             "true",
+	"commit_sha":                "$BITRISE_GIT_COMMIT",
+	"build_server_url":          "$BITRISE_BUILD_URL",
+	"output_format":             "envman",
+	"dotenv_path":               ".env",
+	"strict_mode":               "false",
+	"current_branch":            "$BITRISE_GIT_BRANCH",
+	"retry_count":               "3",
+	"retry_wait_seconds":        "5",
+	"cache_dns":                 "false",
+	"require_mapping":           "false",
+	"allow_empty_response":      "true",
+	"lock_timeout_seconds":      "300",
+	"read_manifest":             "false",
+	"json_status_to_stderr":     "false",
+	"export_fields":             "public_url,build_url,config_url",
+	"log_config":                "true",
+	"api_flavor":                "hockeyapp",
+	"partial_failure_mode":      "fail",
+	"compress_mapping":          "false",
+	"auto_commit_sha":           "false",
+	"emit_step_summary":         "false",
+	"expect_continue":           "false",
+	"hmac_header":               "X-Signature",
+	"verify_server_checksum":    "false",
+	"warmup_connection":         "false",
+	"min_sdk_check_mode":        "at_most",
+	"warnings_as_errors":        "false",
+	"parallel_uploads":          "false",
+	"require_zipalign":          "false",
+	"wait_for_processing":       "false",
+	"processing_timeout":        "300",
+	"auto_detect_notes_type":    "false",
+	"print_curl":                "false",
+	"upload_action":             "create",
+	"log_level":                 "info",
+	"output_metadata_selection": "all",
+	"require_distributable":     "false",
+	"validation_concurrency":    "0",
+	"create_temp_dir":           "false",
+	"idempotent_retry":          "true",
+	"export_install_html":       "false",
+	"forbid_debuggable":         "false",
+	"notes_max_length":          "0",
+	"notes_overflow_mode":       "fail",
+	"write_upload_manifest":     "false",
+	"upload_manifest_path":      "$BITRISE_DEPLOY_DIR/hockeyapp-upload-manifest.json",
+	"require_public_url":        "false",
+	"max_response_bytes":        "10485760",
+	"emit_annotation":           "false",
+	"post_verify":               "false",
+	"retry_max_wait_seconds":    "0",
+	"notes_only":                "false",
+	"disable_keepalive":         "false",
+}
+
+// isDefaultInput reports whether the input is unset or set to its step.yml default.
+func isDefaultInput(key string) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return true
+	}
+	declared, ok := inputDefaults[key]
+	return ok && value == os.ExpandEnv(declared)
+}
+
+// applyProfile sets the inputs of the selected profile as environment variables,
+// the profile values take precedence over the step.yml defaults, but not over the explicitly set inputs.
+// An input explicitly set to its default value is overridden by the profile too.
+func applyProfile() error {
+	profile := strings.TrimSpace(os.Getenv(profileKey))
+	if profile == "" {
+		return nil
+	}
+
+	profiles := map[string]map[string]string{}
+	if err := json.Unmarshal([]byte(os.Getenv(profilesKey)), &profiles); err != nil {
+		return fmt.Errorf("invalid Profiles JSON, error: %v", err)
+	}
+
+	inputs, ok := profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown Profile: %s, available profiles: %s", profile, strings.Join(names, ", "))
+	}
+
+	for _, key := range sortedKeys(inputs) {
+		if key == profileKey || key == profilesKey {
+			return fmt.Errorf("invalid Profiles: profile %s can not set the %s input", profile, key)
+		}
+		if !isDefaultInput(key) {
+			printf("Profile %s input ignored: %s is set explicitly", profile, key)
+			continue
+		}
+		if err := os.Setenv(key, inputs[key]); err != nil {
+			return fmt.Errorf("failed to set %s input of profile %s, error: %v", key, profile, err)
+		}
+	}
+	printf("Profile applied: %s", profile)
+	return nil
+}
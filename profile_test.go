@@ -0,0 +1,123 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var stepYMLInputPattern = regexp.MustCompile(`^  - ([a-z0-9_]+):\s*(.*)$`)
+
+// stepYMLInputDefaults returns the non-empty input defaults of the step.yml inputs section.
+func stepYMLInputDefaults(t *testing.T) map[string]string {
+	content, err := ioutil.ReadFile("step.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := string(content)
+	inputs = inputs[strings.Index(inputs, "\ninputs:\n"):strings.Index(inputs, "\noutputs:\n")]
+
+	defaults := map[string]string{}
+	for _, line := range strings.Split(inputs, "\n") {
+		match := stepYMLInputPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		value := strings.TrimSpace(match[2])
+		if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				t.Fatalf("invalid %s default: %s", match[1], match[2])
+			}
+		} else {
+			value = strings.Trim(value, "'")
+		}
+		if value != "" {
+			defaults[match[1]] = value
+		}
+	}
+	return defaults
+}
+
+func TestInputDefaultsMatchStepYML(t *testing.T) {
+	if want := stepYMLInputDefaults(t); !reflect.DeepEqual(inputDefaults, want) {
+		for key, value := range want {
+			if inputDefaults[key] != value {
+				t.Errorf("inputDefaults[%s] = %q, step.yml default: %q", key, inputDefaults[key], value)
+			}
+		}
+		for key := range inputDefaults {
+			if _, ok := want[key]; !ok {
+				t.Errorf("inputDefaults[%s] is not a step.yml default", key)
+			}
+		}
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	profiles := `{"staging": {"notify": "0", "status": "1", "tags": "staging", "app_id": "staging-app"}, "production": {"notify": "1"}}`
+	tests := []struct {
+		name    string
+		profile string
+		envs    map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "no profile", envs: map[string]string{"notify": "2"}, want: map[string]string{"notify": "2"}},
+		{
+			name:    "profile overrides the defaults",
+			profile: "staging",
+			envs:    map[string]string{"notify": "2", "status": "2"},
+			want:    map[string]string{"notify": "0", "status": "1", "tags": "staging", "app_id": "staging-app"},
+		},
+		{
+			name:    "explicit inputs take precedence",
+			profile: "staging",
+			envs:    map[string]string{"notify": "1", "app_id": "explicit-app"},
+			want:    map[string]string{"notify": "1", "status": "1", "tags": "staging", "app_id": "explicit-app"},
+		},
+		{name: "unknown profile", profile: "beta", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(profileKey, tt.profile)
+			t.Setenv(profilesKey, profiles)
+			for _, key := range []string{"notify", "status", "tags", "app_id"} {
+				t.Setenv(key, tt.envs[key])
+			}
+
+			err := applyProfile()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for key, want := range tt.want {
+				if got := os.Getenv(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyProfileInvalidProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles string
+	}{
+		{name: "invalid JSON", profiles: `{"staging": `},
+		{name: "profile input", profiles: `{"staging": {"profile": "production"}}`},
+		{name: "profiles input", profiles: `{"staging": {"profiles": "{}"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(profileKey, "staging")
+			t.Setenv(profilesKey, tt.profiles)
+			if err := applyProfile(); err == nil {
+				t.Error("applyProfile() succeeded, want an error")
+			}
+		})
+	}
+}
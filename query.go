@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// parseExtraQueryParams parses the comma separated `key=value` pairs,
+// the keys and the values can be percent-encoded.
+func parseExtraQueryParams(s string) (url.Values, error) {
+	params := url.Values{}
+	for _, pair := range splitCommaSeparatedList(s) {
+		split := strings.SplitN(pair, "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid ExtraQueryParams pair: %s, it should be in `key=value` format", pair)
+		}
+
+		key, err := url.QueryUnescape(strings.TrimSpace(split[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ExtraQueryParams key: %s, error: %v", split[0], err)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("invalid ExtraQueryParams pair: %s, the key is empty", pair)
+		}
+		value, err := url.QueryUnescape(strings.TrimSpace(split[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ExtraQueryParams value of %s, error: %v", key, err)
+		}
+		params.Add(key, value)
+	}
+	return params, nil
+}
+
+// setExtraQueryParams sets the extra_query_params input parameters in the query of the request URL,
+// replacing the existing values of the same keys and keeping the other parameters.
+func setExtraQueryParams(request *http.Request) {
+	if len(configs.extraQueryParams) == 0 {
+		return
+	}
+	query := request.URL.Query()
+	for key, values := range configs.extraQueryParams {
+		query[key] = values
+	}
+	request.URL.RawQuery = query.Encode()
+}
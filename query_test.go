@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseExtraQueryParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    url.Values
+		wantErr bool
+	}{
+		{name: "empty", s: "", want: url.Values{}},
+		{name: "pairs", s: "a=1, b=2,a=3", want: url.Values{"a": {"1", "3"}, "b": {"2"}}},
+		{name: "empty value", s: "a=", want: url.Values{"a": {""}}},
+		{name: "value with equal sign", s: "a=b=c", want: url.Values{"a": {"b=c"}}},
+		{name: "percent-encoded", s: "my%20key=a%2Cb", want: url.Values{"my key": {"a,b"}}},
+		{name: "missing equal sign", s: "a", wantErr: true},
+		{name: "empty key", s: "=1", wantErr: true},
+		{name: "invalid key encoding", s: "a%zz=1", wantErr: true},
+		{name: "invalid value encoding", s: "a=%zz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExtraQueryParams(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExtraQueryParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseExtraQueryParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetExtraQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		params url.Values
+		want   string
+	}{
+		{name: "no params", url: "https://example.com/api?b=2&a=1", want: "https://example.com/api?b=2&a=1"},
+		{name: "added params", url: "https://example.com/api", params: url.Values{"a": {"1"}}, want: "https://example.com/api?a=1"},
+		{name: "replaced params", url: "https://example.com/api?a=1&b=2", params: url.Values{"a": {"3", "4"}}, want: "https://example.com/api?a=3&a=4&b=2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{extraQueryParams: tt.params})
+			request, err := http.NewRequest("GET", tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			setExtraQueryParams(request)
+
+			if got := request.URL.String(); got != tt.want {
+				t.Errorf("URL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
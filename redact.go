@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+const redactPatternReplacement = "***"
+
+// parseRedactPatterns compiles the newline separated regular expressions.
+func parseRedactPatterns(s string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, pattern := range splitNewlineSeparatedList(s) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RedactPatterns pattern: %s, error: %v", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, nil
+}
+
+// redactingWriter replaces the matches of the patterns before writing to the underlying writer.
+type redactingWriter struct {
+	writer   io.Writer
+	patterns []*regexp.Regexp
+}
+
+func (w redactingWriter) Write(p []byte) (int, error) {
+	redacted := p
+	for _, pattern := range w.patterns {
+		redacted = pattern.ReplaceAllLiteral(redacted, []byte(redactPatternReplacement))
+	}
+	if _, err := w.writer.Write(redacted); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseRedactPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", s: "", want: 0},
+		{name: "patterns", s: "token=\\w+\n\n  secret  \n", want: 2},
+		{name: "invalid pattern", s: "token=(", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRedactPatterns(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRedactPatterns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("parseRedactPatterns() returned %d patterns, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactingWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns string
+		input    string
+		want     string
+	}{
+		{name: "no patterns", input: "token=abc", want: "token=abc"},
+		{name: "redacted match", patterns: "token=\\w+", input: "url?token=abc&a=1", want: "url?***&a=1"},
+		{name: "multiple patterns", patterns: "abc\nsecret", input: "abc secret abc", want: "*** *** ***"},
+		{name: "literal replacement", patterns: "(a)", input: "a$1", want: "***$1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns, err := parseRedactPatterns(tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			w := redactingWriter{writer: &buf, patterns: patterns}
+
+			n, err := w.Write([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != len(tt.input) {
+				t.Errorf("Write() = %d, want the input length %d", n, len(tt.input))
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("written %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("write failed") }
+
+func TestRedactingWriterError(t *testing.T) {
+	w := redactingWriter{writer: failingWriter{}}
+	if n, err := w.Write([]byte("abc")); err == nil || n != 0 {
+		t.Errorf("Write() = %d, %v, want 0 and the writer error", n, err)
+	}
+}
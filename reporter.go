@@ -0,0 +1,63 @@
+package main
+
+import (
+	"io"
+)
+
+// ProgressReporter receives the events of a deploy,
+// so the deploy logic can be driven without parsing the logs.
+type ProgressReporter interface {
+	// OnValidated is called once the upload request of the artifact is prepared.
+	OnValidated(artifact ArtifactModel)
+	// OnUploadProgress is called while the request body is being sent.
+	OnUploadProgress(sent, total int64)
+	// OnComplete is called with the parsed response of a successful deploy.
+	OnComplete(response ResponseModel)
+}
+
+// logReporter is the ProgressReporter used by the step, it prints the events with the log helpers.
+type logReporter struct {
+	lastDecile int64
+}
+
+func newLogReporter() *logReporter {
+	return &logReporter{}
+}
+
+func (r *logReporter) OnValidated(artifact ArtifactModel) {
+	r.lastDecile = -1
+	printf("Uploading %s (%s)", artifact.Path, artifact.Type)
+}
+
+func (r *logReporter) OnUploadProgress(sent, total int64) {
+	if total <= 0 {
+		return
+	}
+	percent := sent * 100 / total
+	if percent/10 == r.lastDecile {
+		return
+	}
+	r.lastDecile = percent / 10
+	printf(" uploaded %d%% (%d/%d bytes)", percent, sent, total)
+}
+
+func (r *logReporter) OnComplete(response ResponseModel) {
+	donef("Request succeeded")
+}
+
+// progressReader reports the number of bytes read through it to the reporter.
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	total    int64
+	reporter ProgressReporter
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.sent += int64(n)
+		r.reporter.OnUploadProgress(r.sent, r.total)
+	}
+	return n, err
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// recordingReporter records the events of a deploy,
+// events are the names of the called methods in order.
+type recordingReporter struct {
+	mutex     sync.Mutex
+	events    []string
+	validated []ArtifactModel
+	progress  [][2]int64
+	completed []ResponseModel
+}
+
+func (r *recordingReporter) OnValidated(artifact ArtifactModel) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, "OnValidated")
+	r.validated = append(r.validated, artifact)
+}
+
+func (r *recordingReporter) OnUploadProgress(sent, total int64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, "OnUploadProgress")
+	r.progress = append(r.progress, [2]int64{sent, total})
+}
+
+func (r *recordingReporter) OnComplete(response ResponseModel) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, "OnComplete")
+	r.completed = append(r.completed, response)
+}
+
+// compactEvents returns the recorded events with the repeated events collapsed into one.
+func (r *recordingReporter) compactEvents() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var events []string
+	for _, event := range r.events {
+		if len(events) == 0 || events[len(events)-1] != event {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestProgressReader(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		chunkSize    int
+		wantProgress [][2]int64
+	}{
+		{name: "single read", content: "abcd", chunkSize: 8, wantProgress: [][2]int64{{4, 4}}},
+		{name: "multiple reads", content: "abcdefghij", chunkSize: 4, wantProgress: [][2]int64{{4, 10}, {8, 10}, {10, 10}}},
+		{name: "empty body", content: "", chunkSize: 4, wantProgress: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter := &recordingReporter{}
+			reader := &progressReader{reader: bytes.NewReader([]byte(tt.content)), total: int64(len(tt.content)), reporter: reporter}
+
+			var got []byte
+			buf := make([]byte, tt.chunkSize)
+			for {
+				n, err := reader.Read(buf)
+				got = append(got, buf[:n]...)
+				if err != nil {
+					break
+				}
+			}
+			if string(got) != tt.content {
+				t.Errorf("read %q, want %q", got, tt.content)
+			}
+			if !reflect.DeepEqual(reporter.progress, tt.wantProgress) {
+				t.Errorf("progress = %v, want %v", reporter.progress, tt.wantProgress)
+			}
+		})
+	}
+}
+
+func TestCreateRequestReportsProgress(t *testing.T) {
+	reporter := &recordingReporter{}
+	request, _, err := createRequest("POST", "https://example.com", map[string]string{"notes": "notes"}, nil, reporter)
+	if err != nil {
+		t.Fatalf("createRequest() error = %v", err)
+	}
+	if _, err := ioutil.ReadAll(request.Body); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reporter.progress) == 0 {
+		t.Fatal("no upload progress reported")
+	}
+	last := reporter.progress[len(reporter.progress)-1]
+	if last[0] != request.ContentLength || last[1] != request.ContentLength {
+		t.Errorf("last progress = %v, want %d/%d", last, request.ContentLength, request.ContentLength)
+	}
+}
+
+func TestPerformRequestReportsEvents(t *testing.T) {
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := ioutil.WriteFile(apkPath, bytes.Repeat([]byte("apk"), 64*1024), 0600); err != nil {
+		t.Fatal(err)
+	}
+	artifact := ArtifactModel{Type: artifactTypeAPK, Path: apkPath, Field: "ipa"}
+
+	tests := []struct {
+		name       string
+		status     int
+		wantEvents []string
+	}{
+		{name: "success", status: 201, wantEvents: []string{"OnValidated", "OnUploadProgress", "OnComplete"}},
+		{name: "failure", status: 500, wantEvents: []string{"OnValidated", "OnUploadProgress"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{})
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, err := io.Copy(ioutil.Discard, r.Body); err != nil {
+					t.Errorf("failed to read request body: %v", err)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"id": 1, "public_url": "https://rink.hockeyapp.net/apps/1"}`)
+			}))
+			defer ts.Close()
+
+			reporter := &recordingReporter{}
+			_, _ = performRequest(context.Background(), ts.Client(), multipartRequest("POST", ts.URL, map[string]string{"notes": "notes"}, map[string]string{"ipa": apkPath}, reporter), artifact, "key", reporter)
+
+			if got := reporter.compactEvents(); !reflect.DeepEqual(got, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", got, tt.wantEvents)
+			}
+			if len(reporter.progress) < 2 {
+				t.Errorf("progress = %v, want the progress of several reads", reporter.progress)
+			}
+		})
+	}
+}
+
+func TestLogReporterDeciles(t *testing.T) {
+	tests := []struct {
+		name        string
+		sent        []int64
+		total       int64
+		wantDeciles []int64
+	}{
+		{name: "every decile once", sent: []int64{1, 5, 10, 15, 100}, total: 100, wantDeciles: []int64{-1, 0, 1, 10}},
+		{name: "unknown total", sent: []int64{1, 2}, total: 0, wantDeciles: []int64{-1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter := newLogReporter()
+			reporter.OnValidated(ArtifactModel{Path: "app.apk", Type: artifactTypeAPK})
+			deciles := []int64{reporter.lastDecile}
+			for _, sent := range tt.sent {
+				reporter.OnUploadProgress(sent, tt.total)
+				if reporter.lastDecile != deciles[len(deciles)-1] {
+					deciles = append(deciles, reporter.lastDecile)
+				}
+			}
+			if !reflect.DeepEqual(deciles, tt.wantDeciles) {
+				t.Errorf("deciles = %v, want %v", deciles, tt.wantDeciles)
+			}
+		})
+	}
+}
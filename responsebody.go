@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// defaultMaxResponseBytes is the MaxResponseBytes if the max_response_bytes input is not set.
+const defaultMaxResponseBytes = 10 * 1024 * 1024
+
+// readResponseBody reads at most MaxResponseBytes of the response body, the rest is dropped with a warning.
+// The body is read completely if MaxResponseBytes is 0.
+func readResponseBody(response *http.Response) ([]byte, error) {
+	if configs.MaxResponseBytes == 0 {
+		return ioutil.ReadAll(response.Body)
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(response.Body, configs.MaxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(contents)) > configs.MaxResponseBytes {
+		warnf("Response body of %s is larger than %d bytes, truncating it", printableRequestURL(response.Request.URL), configs.MaxResponseBytes)
+		contents = contents[:configs.MaxResponseBytes]
+	}
+	return contents, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestReadResponseBody(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxResponseBytes int64
+		body             string
+		want             string
+	}{
+		{name: "unbounded", maxResponseBytes: 0, body: "0123456789", want: "0123456789"},
+		{name: "smaller body", maxResponseBytes: 20, body: "0123456789", want: "0123456789"},
+		{name: "exact size", maxResponseBytes: 10, body: "0123456789", want: "0123456789"},
+		{name: "truncated body", maxResponseBytes: 4, body: "0123456789", want: "0123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{MaxResponseBytes: tt.maxResponseBytes})
+			response := &http.Response{
+				Body:    ioutil.NopCloser(strings.NewReader(tt.body)),
+				Request: &http.Request{URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/api"}},
+			}
+
+			got, err := readResponseBody(response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("readResponseBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statusCodeError is returned if the server responds with a non-success status code.
+type statusCodeError struct {
+	StatusCode int
+}
+
+func (e statusCodeError) Error() string {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Sprintf("Performing request failed, status code: %d: the API token is invalid, expired or revoked, "+
+			"check the api_token input and create a new token on the HockeyApp Account Settings > API Tokens page if needed", e.StatusCode)
+	case http.StatusForbidden:
+		return fmt.Sprintf("Performing request failed, status code: %d: the API token has no permission for this app, "+
+			"app-level tokens only work for the app they were created for and need Upload rights, "+
+			"use an account-level token with Upload or Full Access rights to deploy to other apps", e.StatusCode)
+	default:
+		return fmt.Sprintf("Performing request failed, status code: %d", e.StatusCode)
+	}
+}
+
+// loginPageError is returned if the server responds with an HTML login page instead of the API response,
+// as it does if the API token is missing or invalid.
+type loginPageError struct {
+	StatusCode int
+}
+
+func (e loginPageError) Error() string {
+	return fmt.Sprintf("Performing request failed, status code: %d: authentication required, the server responded with a login page: "+
+		"the API token is missing or invalid, check the api_token input", e.StatusCode)
+}
+
+// loginPageMarkers are the (lowercased) fragments of the HTML login pages.
+var loginPageMarkers = []string{
+	`type="password"`,
+	"type='password'",
+	"sign in",
+	"log in",
+	"login",
+}
+
+// isLoginPageResponse reports whether the response is an HTML page with a login form.
+func isLoginPageResponse(contentType string, body []byte) bool {
+	lowerBody := strings.ToLower(string(bytes.TrimSpace(body)))
+	isHTML := strings.HasPrefix(strings.ToLower(contentType), "text/html") ||
+		strings.HasPrefix(lowerBody, "<!doctype html") || strings.HasPrefix(lowerBody, "<html")
+	if !isHTML {
+		return false
+	}
+	for _, marker := range loginPageMarkers {
+		if strings.Contains(lowerBody, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAuthError reports whether the request was rejected because of the API token,
+// retrying the upload or uploading other artifacts with the same token will fail too.
+func isAuthError(err error) bool {
+	var loginErr loginPageError
+	if errors.As(err, &loginErr) {
+		return true
+	}
+
+	var statusErr statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// bodyMatchError is returned if the response body matches the retry_on_body_regex input.
+type bodyMatchError struct {
+	StatusCode int
+}
+
+func (e bodyMatchError) Error() string {
+	return fmt.Sprintf("Performing request failed, status code: %d: the response body matches the retry pattern", e.StatusCode)
+}
+
+// requestSentError is returned if the request failed after it was completely written,
+// the server might have processed it.
+type requestSentError struct {
+	err error
+}
+
+func (e requestSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e requestSentError) Unwrap() error {
+	return e.err
+}
+
+func isRequestSentError(err error) bool {
+	var sentErr requestSentError
+	return errors.As(err, &sentErr)
+}
+
+// isIdempotentUpload reports whether repeating the upload can not create a duplicate version:
+// the upload has a user provided idempotency key or it updates an existing version.
+// The generated idempotency key does not make the upload idempotent: it is sent with every upload,
+// but only a deduplicating gateway in front of the API honors it (the HockeyApp API ignores it),
+// and setting the idempotency_key input is how the user declares that such a gateway is in place.
+// Counting the generated key would make every upload idempotent and IdempotentRetry a no-op.
+func isIdempotentUpload() bool {
+	return configs.IdempotencyKey != "" || configs.UploadAction == uploadActionUpdate
+}
+
+// retryWait returns the wait before the retry following the failed attempt (counted from 0):
+// the RetryWait if RetryMaxWait is not set, otherwise the RetryWait doubled after every attempt,
+// capped at RetryMaxWait, with a random jitter of up to the half of the interval.
+func retryWait(attempt int) time.Duration {
+	if configs.RetryMaxWait <= 0 {
+		return configs.RetryWait
+	}
+
+	wait := configs.RetryWait
+	for i := 0; i < attempt && wait < configs.RetryMaxWait; i++ {
+		wait *= 2
+	}
+	if wait > configs.RetryMaxWait {
+		wait = configs.RetryMaxWait
+	}
+	if half := int64(wait / 2); half > 0 {
+		wait = time.Duration(half + rand.Int63n(half+1))
+	}
+	return wait
+}
+
+func isDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// isRetryableError reports whether the failed upload may succeed if retried:
+// DNS resolution failures, other network errors, 5xx responses and responses matching the retry pattern are retryable.
+func isRetryableError(err error) bool {
+	if isHostNotAllowedError(err) {
+		return false
+	}
+	if isDNSError(err) {
+		return true
+	}
+
+	var bodyErr bodyMatchError
+	if errors.As(err, &bodyErr) {
+		return true
+	}
+
+	var statusErr statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryRequest runs the attempts of a request until one succeeds, or fails with a not retryable error,
+// or the RetryCount is exceeded, and returns the number of attempts made.
+// The upload attempts should be counted by the attempt with countAttempt.
+// With IdempotentRetry a sent, but failed request is only retried if it is idempotent.
+func retryRequest(ctx context.Context, idempotent bool, attempt func() error) (int, error) {
+	for i := 0; ; i++ {
+		err := attempt()
+		if err == nil {
+			return i + 1, nil
+		}
+		if ctx.Err() != nil {
+			return i + 1, totalTimeoutError(ctx, err)
+		}
+		if i >= configs.RetryCount || !isRetryableError(err) {
+			return i + 1, err
+		}
+		if configs.IdempotentRetry && isRequestSentError(err) && !idempotent {
+			warnf("Attempt %d/%d failed after the request was sent, not retrying as it might create a duplicate version: %v", i+1, configs.RetryCount+1, err)
+			return i + 1, err
+		}
+
+		if isDNSError(err) {
+			warnf("Transient DNS issue (attempt %d/%d): %v", i+1, configs.RetryCount+1, err)
+		} else {
+			warnf("Attempt %d/%d failed: %v", i+1, configs.RetryCount+1, err)
+		}
+		wait := retryWait(i)
+		printf("Retrying in %s...", wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return i + 1, totalTimeoutError(ctx, err)
+		}
+	}
+}
+
+// countAttempt counts an upload request attempt for the HOCKEYAPP_DEPLOY_ATTEMPTS output.
+func countAttempt() {
+	runStateMutex.Lock()
+	attemptCount++
+	runStateMutex.Unlock()
+}
+
+// totalTimeoutError is returned if the step's context is done, to distinguish it
+// from the failure of a single request.
+func totalTimeoutError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("total timeout (%s) exceeded, aborting the deploy, last error: %v", configs.TotalTimeout, err)
+	}
+	return fmt.Errorf("deploy aborted: %v, last error: %v", ctx.Err(), err)
+}
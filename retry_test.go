@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryWait(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryWait    time.Duration
+		retryMaxWait time.Duration
+		attempt      int
+		wantMin      time.Duration
+		wantMax      time.Duration
+	}{
+		{name: "constant wait without max wait", retryWait: 5 * time.Second, attempt: 3, wantMin: 5 * time.Second, wantMax: 5 * time.Second},
+		{name: "first retry", retryWait: 5 * time.Second, retryMaxWait: time.Minute, attempt: 0, wantMin: 2500 * time.Millisecond, wantMax: 5 * time.Second},
+		{name: "doubled wait", retryWait: 5 * time.Second, retryMaxWait: time.Minute, attempt: 2, wantMin: 10 * time.Second, wantMax: 20 * time.Second},
+		{name: "capped wait", retryWait: 5 * time.Second, retryMaxWait: 30 * time.Second, attempt: 10, wantMin: 15 * time.Second, wantMax: 30 * time.Second},
+		{name: "max wait below the wait", retryWait: 5 * time.Second, retryMaxWait: 2 * time.Second, attempt: 0, wantMin: time.Second, wantMax: 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{RetryWait: tt.retryWait, RetryMaxWait: tt.retryMaxWait})
+			for i := 0; i < 20; i++ {
+				if got := retryWait(tt.attempt); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("retryWait(%d) = %s, want between %s and %s", tt.attempt, got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+// sequenceServer responds to the n-th request with the n-th response, the last one is repeated.
+type sequenceServer struct {
+	mutex     sync.Mutex
+	responses []testResponse
+	requests  []*http.Request
+}
+
+type testResponse struct {
+	status      int
+	contentType string
+	location    string
+	body        string
+}
+
+func (s *sequenceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests = append(s.requests, r)
+	response := s.responses[len(s.responses)-1]
+	if len(s.requests) <= len(s.responses) {
+		response = s.responses[len(s.requests)-1]
+	}
+	s.mutex.Unlock()
+
+	if response.contentType != "" {
+		w.Header().Set("Content-Type", response.contentType)
+	}
+	if response.location != "" {
+		w.Header().Set("Location", response.location)
+	}
+	w.WriteHeader(response.status)
+	fmt.Fprint(w, response.body)
+}
+
+func TestPerformRequestWithRetry(t *testing.T) {
+	okBody := `{"id": 1, "public_url": "https://rink.hockeyapp.net/apps/1"}`
+	tests := []struct {
+		name           string
+		responses      []testResponse
+		retryCount     int
+		retryBodyRegex string
+		wantRequests   int
+		wantErr        bool
+		wantAuthErr    bool
+	}{
+		{name: "success", responses: []testResponse{{status: 201, body: okBody}}, retryCount: 3, wantRequests: 1},
+		{name: "server error retried", responses: []testResponse{{status: 500}, {status: 503}, {status: 201, body: okBody}}, retryCount: 3, wantRequests: 3},
+		{name: "retries exhausted", responses: []testResponse{{status: 500}}, retryCount: 2, wantRequests: 3, wantErr: true},
+		{name: "client error not retried", responses: []testResponse{{status: 422}}, retryCount: 3, wantRequests: 1, wantErr: true},
+		{name: "invalid token not retried", responses: []testResponse{{status: 401}}, retryCount: 3, wantRequests: 1, wantErr: true, wantAuthErr: true},
+		{name: "login page not retried", responses: []testResponse{{status: 200, contentType: "text/html", body: `<html><form><input type="password"></form></html>`}}, retryCount: 3, wantRequests: 1, wantErr: true, wantAuthErr: true},
+		{name: "body pattern retried", responses: []testResponse{{status: 200, body: `{"error": "try again later"}`}, {status: 201, body: okBody}}, retryCount: 3, retryBodyRegex: "try again", wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ConfigsModel{APIToken: "token", RetryCount: tt.retryCount}
+			if tt.retryBodyRegex != "" {
+				c.retryBodyRegexp = regexp.MustCompile(tt.retryBodyRegex)
+			}
+			setConfigs(t, c)
+			server := &sequenceServer{responses: tt.responses}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+
+			response, err := performRequestWithRetry(context.Background(), ts.Client(), multipartRequest("POST", ts.URL, map[string]string{"notes": "notes"}, nil, nil), ArtifactModel{}, "key", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("performRequestWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if isAuthError(err) != tt.wantAuthErr {
+				t.Errorf("isAuthError(%v) = %v, want %v", err, isAuthError(err), tt.wantAuthErr)
+			}
+			if len(server.requests) != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", len(server.requests), tt.wantRequests)
+			}
+			for _, r := range server.requests {
+				if r.Header.Get("Idempotency-Key") != "key" || r.Header.Get("X-HockeyAppToken") != "token" {
+					t.Errorf("headers = %v, want the idempotency key and the token on every attempt", r.Header)
+				}
+			}
+			if !tt.wantErr && response.ID != 1 {
+				t.Errorf("response ID = %d, want 1", response.ID)
+			}
+		})
+	}
+}
+
+func TestRetryRequest(t *testing.T) {
+	sentErr := requestSentError{err: &timeoutError{}}
+	tests := []struct {
+		name            string
+		idempotentRetry bool
+		idempotent      bool
+		errs            []error
+		wantAttempts    int
+		wantErr         bool
+	}{
+		{name: "network error retried", errs: []error{&timeoutError{}, nil}, wantAttempts: 2},
+		{name: "sent request retried without idempotent retry", errs: []error{sentErr, nil}, wantAttempts: 2},
+		{name: "sent request not retried if not idempotent", idempotentRetry: true, errs: []error{sentErr}, wantAttempts: 1, wantErr: true},
+		{name: "sent idempotent request retried", idempotentRetry: true, idempotent: true, errs: []error{sentErr, nil}, wantAttempts: 2},
+		{name: "DNS error retried", errs: []error{&net.DNSError{Err: "server misbehaving", Name: "rink.hockeyapp.net", IsTemporary: true}, nil}, wantAttempts: 2},
+		{name: "host not allowed not retried", errs: []error{hostNotAllowedError{Host: "example.com"}}, wantAttempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{RetryCount: 3, IdempotentRetry: tt.idempotentRetry})
+			calls := 0
+			attempts, err := retryRequest(context.Background(), tt.idempotent, func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retryRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("attempts = %d (calls: %d), want %d", attempts, calls, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryRequestTotalTimeout(t *testing.T) {
+	setConfigs(t, ConfigsModel{RetryCount: 3, RetryWait: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := retryRequest(ctx, true, func() error { return statusCodeError{StatusCode: 500} })
+	if err == nil || !strings.HasPrefix(err.Error(), "total timeout") {
+		t.Fatalf("retryRequest() error = %v, want the total timeout error", err)
+	}
+}
+
+// timeoutError is a retryable network error.
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+func TestIsIdempotentUpload(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs ConfigsModel
+		want    bool
+	}{
+		{name: "user provided idempotency key", configs: ConfigsModel{IdempotencyKey: "deploy-1"}, want: true},
+		{name: "update action", configs: ConfigsModel{UploadAction: uploadActionUpdate}, want: true},
+		{name: "generated idempotency key", configs: ConfigsModel{}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, tt.configs)
+			if got := isIdempotentUpload(); got != tt.want {
+				t.Errorf("isIdempotentUpload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const shortenerTimeout = 10 * time.Second
+
+// shortenerResponseFields are the JSON response fields checked for the short link, in order.
+var shortenerResponseFields = []string{"short_url", "shortUrl", "short_link", "shortLink", "link", "url"}
+
+// parseShortURL returns the short link from a JSON object response, or from a plain text response body.
+func parseShortURL(body []byte) (string, error) {
+	body = bytes.TrimSpace(body)
+
+	shortURL := string(body)
+	if bytes.HasPrefix(body, []byte("{")) {
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return "", fmt.Errorf("failed to parse response body, error: %v", err)
+		}
+		shortURL = ""
+		for _, field := range shortenerResponseFields {
+			if s, ok := fields[field].(string); ok && s != "" {
+				shortURL = s
+				break
+			}
+		}
+		if shortURL == "" {
+			return "", fmt.Errorf("no short link found in the response, expected one of the fields: %s", strings.Join(shortenerResponseFields, ", "))
+		}
+	}
+
+	if u, err := url.Parse(shortURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid short link in the response: %q", shortURL)
+	}
+	return shortURL, nil
+}
+
+// shortenURL posts the URL to the shortener as a `{"url": "..."}` JSON object and returns the short link.
+func shortenURL(ctx context.Context, client *http.Client, shortenerURL, longURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, shortenerTimeout)
+	defer cancel()
+
+	payload, err := json.Marshal(map[string]string{"url": longURL})
+	if err != nil {
+		return "", err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", shortenerURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+
+	body, err := readResponseBody(response)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body, error: %v", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", fmt.Errorf("status code: %d, body: %s", response.StatusCode, body)
+	}
+	return parseShortURL(body)
+}
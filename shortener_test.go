@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseShortURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain text", body: "https://sho.rt/abc\n", want: "https://sho.rt/abc"},
+		{name: "short_url field", body: `{"short_url": "https://sho.rt/abc"}`, want: "https://sho.rt/abc"},
+		{name: "field order", body: `{"url": "https://long/url", "shortLink": "https://sho.rt/abc"}`, want: "https://sho.rt/abc"},
+		{name: "no short link field", body: `{"id": "abc"}`, wantErr: true},
+		{name: "invalid JSON", body: `{"short_url": `, wantErr: true},
+		{name: "not a URL", body: "abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseShortURL([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseShortURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseShortURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortenURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "short link", status: 201, body: `{"link": "https://sho.rt/abc"}`, want: "https://sho.rt/abc"},
+		{name: "server error", status: 500, body: "error", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload map[string]string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("failed to decode the request body: %v", err)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer ts.Close()
+
+			got, err := shortenURL(context.Background(), ts.Client(), ts.URL, "https://rink.hockeyapp.net/apps/1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("shortenURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("shortenURL() = %q, want %q", got, tt.want)
+			}
+			if payload["url"] != "https://rink.hockeyapp.net/apps/1" {
+				t.Errorf("request payload = %v, want the long URL", payload)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	zipEOCDSignature       = 0x06054b50
+	zipEOCDSize            = 22
+	zipMaxEOCDCommentSize  = 0xffff
+	apkSigningBlockMagic   = "APK Sig Block 42"
+	apkSignatureSchemeV2ID = 0x7109871a
+	apkSignatureSchemeV3ID = 0xf05368c0
+)
+
+var errNoSigningBlock = errors.New("no APK Signing Block found")
+
+// normalizeFingerprint lowercases the SHA-256 fingerprint and removes the colon separators,
+// it returns an empty string if the fingerprint is invalid.
+func normalizeFingerprint(fingerprint string) string {
+	normalized := strings.ToLower(strings.Replace(strings.TrimSpace(fingerprint), ":", "", -1))
+	if b, err := hex.DecodeString(normalized); err != nil || len(b) != sha256.Size {
+		return ""
+	}
+	return normalized
+}
+
+func certFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// signingCertFingerprints returns the SHA-256 fingerprints of the signer certificates of the artifact,
+// read from the v2/v3 APK Signing Block or, if the artifact has none, from the v1 (JAR) signature.
+func signingCertFingerprints(pth string) ([]string, error) {
+	certs, err := apkSigningBlockCerts(pth)
+	if err == errNoSigningBlock {
+		certs, err = jarSignatureCerts(pth)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no signing certificate found in %s", pth)
+	}
+
+	var fingerprints []string
+	for _, cert := range certs {
+		fingerprints = append(fingerprints, certFingerprint(cert))
+	}
+	return fingerprints, nil
+}
+
+// verifySigningCert fails if any signer certificate of the artifact has a different SHA-256 fingerprint than expected.
+func verifySigningCert(pth, expected string) error {
+	fingerprints, err := signingCertFingerprints(pth)
+	if err != nil {
+		return err
+	}
+	for _, fingerprint := range fingerprints {
+		if fingerprint != normalizeFingerprint(expected) {
+			return fmt.Errorf("signing certificate SHA-256 fingerprint (%s) of %s does not match the expected fingerprint (%s)", fingerprint, pth, expected)
+		}
+	}
+	return nil
+}
+
+// apkSigningBlockCerts returns the first certificate of every v2/v3 signer in the APK Signing Block,
+// stored right before the ZIP Central Directory.
+func apkSigningBlockCerts(pth string) ([][]byte, error) {
+	f, err := os.Open(pth)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			warnf("Failed to close file (%s), error: %v", pth, err)
+		}
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	tailSize := int64(zipEOCDSize + zipMaxEOCDCommentSize)
+	if tailSize > info.Size() {
+		tailSize = info.Size()
+	}
+	tail := make([]byte, tailSize)
+	if _, err := f.ReadAt(tail, info.Size()-tailSize); err != nil {
+		return nil, err
+	}
+
+	eocd := -1
+	for i := len(tail) - zipEOCDSize; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) == zipEOCDSignature {
+			eocd = i
+			break
+		}
+	}
+	if eocd < 0 {
+		return nil, fmt.Errorf("%s is not a valid zip file", pth)
+	}
+	centralDirOffset := int64(binary.LittleEndian.Uint32(tail[eocd+16:]))
+
+	if centralDirOffset < 24 {
+		return nil, errNoSigningBlock
+	}
+	footer := make([]byte, 24)
+	if _, err := f.ReadAt(footer, centralDirOffset-24); err != nil {
+		return nil, err
+	}
+	if string(footer[8:]) != apkSigningBlockMagic {
+		return nil, errNoSigningBlock
+	}
+
+	blockSize := int64(binary.LittleEndian.Uint64(footer))
+	if blockSize < 24 || blockSize+8 > centralDirOffset {
+		return nil, fmt.Errorf("invalid APK Signing Block size: %d", blockSize)
+	}
+	block := make([]byte, blockSize-24)
+	if _, err := f.ReadAt(block, centralDirOffset-blockSize); err != nil {
+		return nil, err
+	}
+
+	for len(block) > 0 {
+		if len(block) < 12 {
+			return nil, errors.New("invalid APK Signing Block entry")
+		}
+		pairSize := binary.LittleEndian.Uint64(block)
+		if pairSize < 4 || pairSize > uint64(len(block)-8) {
+			return nil, errors.New("invalid APK Signing Block entry size")
+		}
+		id := binary.LittleEndian.Uint32(block[8:])
+		value := block[12 : 8+pairSize]
+		block = block[8+pairSize:]
+
+		if id == apkSignatureSchemeV2ID || id == apkSignatureSchemeV3ID {
+			return signatureSchemeCerts(value)
+		}
+	}
+	return nil, errNoSigningBlock
+}
+
+// lengthPrefixed splits the uint32 length prefixed slice off the start of data.
+func lengthPrefixed(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, errors.New("invalid length prefixed value")
+	}
+	size := binary.LittleEndian.Uint32(data)
+	if uint64(size) > uint64(len(data)-4) {
+		return nil, nil, errors.New("invalid length prefixed value size")
+	}
+	return data[4 : 4+size], data[4+size:], nil
+}
+
+// signatureSchemeCerts parses the signers of a v2/v3 signature scheme block,
+// both schemes start the signed data with the digests and the certificates.
+func signatureSchemeCerts(value []byte) ([][]byte, error) {
+	signers, _, err := lengthPrefixed(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs [][]byte
+	for len(signers) > 0 {
+		var signer []byte
+		if signer, signers, err = lengthPrefixed(signers); err != nil {
+			return nil, err
+		}
+		signedData, _, err := lengthPrefixed(signer)
+		if err != nil {
+			return nil, err
+		}
+		_, rest, err := lengthPrefixed(signedData)
+		if err != nil {
+			return nil, err
+		}
+		signerCerts, _, err := lengthPrefixed(rest)
+		if err != nil {
+			return nil, err
+		}
+		cert, _, err := lengthPrefixed(signerCerts)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+type pkcs7ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type pkcs7SignedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	ContentInfo      asn1.RawValue
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      asn1.RawValue
+}
+
+// jarSignatureCerts returns the first certificate of every v1 (JAR) signature block in the META-INF directory.
+func jarSignatureCerts(pth string) ([][]byte, error) {
+	r, err := zip.OpenReader(pth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s, error: %v", pth, err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			warnf("Failed to close %s, error: %v", pth, err)
+		}
+	}()
+
+	var certs [][]byte
+	for _, f := range r.File {
+		if path.Dir(f.Name) != "META-INF" {
+			continue
+		}
+		switch strings.ToUpper(path.Ext(f.Name)) {
+		case ".RSA", ".DSA", ".EC":
+		default:
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s, error: %v", f.Name, err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		if cerr := rc.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s, error: %v", f.Name, err)
+		}
+
+		cert, err := pkcs7FirstCert(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s, error: %v", f.Name, err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func pkcs7FirstCert(data []byte) ([]byte, error) {
+	var contentInfo pkcs7ContentInfo
+	if _, err := asn1.Unmarshal(data, &contentInfo); err != nil {
+		return nil, err
+	}
+	var signedData pkcs7SignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+	if len(signedData.Certificates.Bytes) == 0 {
+		return nil, errors.New("no certificates in the signature block")
+	}
+	var cert asn1.RawValue
+	if _, err := asn1.Unmarshal(signedData.Certificates.Bytes, &cert); err != nil {
+		return nil, err
+	}
+	return cert.FullBytes, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testdataCertFingerprint is the SHA-256 fingerprint of the certificate the testdata APKs are signed with.
+const testdataCertFingerprint = "CA:69:D2:E9:9A:F9:BC:B9:58:E7:A9:43:07:AD:49:C8:A4:73:A1:56:0B:A4:E3:59:E0:50:66:21:E9:DC:0B:F7"
+
+func TestNormalizeFingerprint(t *testing.T) {
+	tests := []struct {
+		fingerprint string
+		want        string
+	}{
+		{fingerprint: testdataCertFingerprint, want: "ca69d2e99af9bcb958e7a94307ad49c8a473a1560ba4e359e0506621e9dc0bf7"},
+		{fingerprint: " ca69d2e99af9bcb958e7a94307ad49c8a473a1560ba4e359e0506621e9dc0bf7\n", want: "ca69d2e99af9bcb958e7a94307ad49c8a473a1560ba4e359e0506621e9dc0bf7"},
+		{fingerprint: "CA:69:D2", want: ""},
+		{fingerprint: "not a fingerprint", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fingerprint, func(t *testing.T) {
+			if got := normalizeFingerprint(tt.fingerprint); got != tt.want {
+				t.Errorf("normalizeFingerprint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigningCertFingerprints(t *testing.T) {
+	fingerprint := normalizeFingerprint(testdataCertFingerprint)
+	tests := []struct {
+		name    string
+		apkPath string
+		want    []string
+		wantErr bool
+	}{
+		{name: "v1 signature", apkPath: "testdata/signed-v1.apk", want: []string{fingerprint}},
+		{name: "v2 signature", apkPath: "testdata/signed-v2.apk", want: []string{fingerprint}},
+		{name: "unsigned", apkPath: "testdata/app.apk", wantErr: true},
+		{name: "missing", apkPath: "testdata/missing.apk", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signingCertFingerprints(tt.apkPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("signingCertFingerprints() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("signingCertFingerprints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySigningCert(t *testing.T) {
+	tests := []struct {
+		name     string
+		apkPath  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "v1 match", apkPath: "testdata/signed-v1.apk", expected: testdataCertFingerprint},
+		{name: "v2 match", apkPath: "testdata/signed-v2.apk", expected: testdataCertFingerprint},
+		{name: "mismatch", apkPath: "testdata/signed-v2.apk", expected: "00" + normalizeFingerprint(testdataCertFingerprint)[2:], wantErr: true},
+		{name: "unsigned", apkPath: "testdata/app.apk", expected: testdataCertFingerprint, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verifySigningCert(tt.apkPath, tt.expected); (err != nil) != tt.wantErr {
+				t.Errorf("verifySigningCert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const slackNotifyTimeout = 10 * time.Second
+
+const (
+	defaultSlackSuccessMessageTemplate = "HockeyApp deploy {status}: {version} {public_url}"
+	defaultSlackFailureMessageTemplate = "HockeyApp deploy {status}: {error}"
+)
+
+// slackMessageTemplate returns the template of the status: the status specific template if set,
+// otherwise the SlackMessageTemplate, otherwise the default template of the status.
+func slackMessageTemplate(status StatusModel) string {
+	success := status.Status == hockeyAppDeployStatusSuccess
+	if success && configs.SlackSuccessMessageTemplate != "" {
+		return configs.SlackSuccessMessageTemplate
+	}
+	if !success && configs.SlackFailureMessageTemplate != "" {
+		return configs.SlackFailureMessageTemplate
+	}
+	if configs.SlackMessageTemplate != "" {
+		return configs.SlackMessageTemplate
+	}
+	if success {
+		return defaultSlackSuccessMessageTemplate
+	}
+	return defaultSlackFailureMessageTemplate
+}
+
+// renderSlackMessage substitutes the status into the template:
+// {status}, {version}, {public_url}, {build_url} and {error} are replaced as is.
+func renderSlackMessage(template string, status StatusModel) string {
+	return strings.NewReplacer(
+		"{status}", status.Status,
+		"{version}", status.Version,
+		"{public_url}", status.PublicURL,
+		"{build_url}", status.BuildURL,
+		"{error}", status.Error,
+	).Replace(template)
+}
+
+// notifySlack posts the message of the status to the SlackWebhookURL,
+// failing to post only prints a warning.
+func notifySlack(status StatusModel) {
+	if configs.SlackWebhookURL == "" {
+		return
+	}
+
+	message := renderSlackMessage(slackMessageTemplate(status), status)
+	message = redact(message, []string{configs.APIToken, configs.HMACSecret})
+	if err := postSlackMessage(configs.SlackWebhookURL, message); err != nil {
+		warnf("Failed to send the Slack notification, error: %v", err)
+		return
+	}
+	printf("Slack notification sent")
+}
+
+func postSlackMessage(webhookURL, message string) error {
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]string{"text": message})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), slackNotifyTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+	if _, err := io.Copy(ioutil.Discard, response.Body); err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("status code: %d", response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlackMessageTemplate(t *testing.T) {
+	success := StatusModel{Status: hockeyAppDeployStatusSuccess}
+	failure := StatusModel{Status: "failed"}
+	tests := []struct {
+		name    string
+		configs ConfigsModel
+		status  StatusModel
+		want    string
+	}{
+		{name: "default success template", status: success, want: defaultSlackSuccessMessageTemplate},
+		{name: "default failure template", status: failure, want: defaultSlackFailureMessageTemplate},
+		{name: "common template", configs: ConfigsModel{SlackMessageTemplate: "common"}, status: failure, want: "common"},
+		{name: "success template", configs: ConfigsModel{SlackMessageTemplate: "common", SlackSuccessMessageTemplate: "success"}, status: success, want: "success"},
+		{name: "failure template", configs: ConfigsModel{SlackMessageTemplate: "common", SlackFailureMessageTemplate: "failure"}, status: failure, want: "failure"},
+		{name: "success template on failure", configs: ConfigsModel{SlackSuccessMessageTemplate: "success"}, status: failure, want: defaultSlackFailureMessageTemplate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, tt.configs)
+			if got := slackMessageTemplate(tt.status); got != tt.want {
+				t.Errorf("slackMessageTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSlackMessage(t *testing.T) {
+	status := StatusModel{Status: "success", Version: "1.2.3 (42)", PublicURL: "https://public", BuildURL: "https://build", Error: "none"}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: "", want: ""},
+		{template: "{status}: {version} {public_url} {build_url} {error}", want: "success: 1.2.3 (42) https://public https://build none"},
+		{template: "{unknown} {status}{status}", want: "{unknown} successsuccess"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			if got := renderSlackMessage(tt.template, status); got != tt.want {
+				t.Errorf("renderSlackMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifySlack(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		template   string
+		want       string
+	}{
+		{name: "posted message", statusCode: http.StatusOK, template: "{status}: {version}", want: "success: 1.2.3"},
+		{name: "redacted secrets", statusCode: http.StatusOK, template: "{error}", want: "token " + redactedValue + " secret " + redactedValue},
+		{name: "failed post", statusCode: http.StatusInternalServerError, template: "{status}", want: "success"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request = %s %s, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+				}
+				var payload map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("invalid payload: %v", err)
+				}
+				got = payload["text"]
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer ts.Close()
+			setConfigs(t, ConfigsModel{SlackWebhookURL: ts.URL, SlackMessageTemplate: tt.template, APIToken: "api-token", HMACSecret: "hmac-secret"})
+
+			notifySlack(StatusModel{Status: "success", Version: "1.2.3", Error: "token api-token secret hmac-secret"})
+
+			if got != tt.want {
+				t.Errorf("posted text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostSlackMessageStatusCode(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		wantErr    bool
+	}{
+		{statusCode: http.StatusOK},
+		{statusCode: http.StatusNoContent},
+		{statusCode: http.StatusFound, wantErr: true},
+		{statusCode: http.StatusForbidden, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.statusCode == http.StatusFound {
+					w.Header().Set("Location", "/")
+				}
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer ts.Close()
+
+			if err := postSlackMessage(ts.URL+"/hook", "message"); (err != nil) != tt.wantErr {
+				t.Errorf("postSlackMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// UploadStatsModel ...
+type UploadStatsModel struct {
+	Size     int64
+	Duration time.Duration
+}
+
+// Add ...
+func (s UploadStatsModel) Add(other UploadStatsModel) UploadStatsModel {
+	return UploadStatsModel{Size: s.Size + other.Size, Duration: s.Duration + other.Duration}
+}
+
+// SpeedMBps returns the average upload speed in megabytes (10^6 bytes) per second,
+// 0 if the duration is not positive.
+func (s UploadStatsModel) SpeedMBps() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Size) / 1e6 / s.Duration.Seconds()
+}
+
+func (s UploadStatsModel) String() string {
+	return fmt.Sprintf("%d bytes in %s (%.2f MB/s)", s.Size, s.Duration.Round(time.Millisecond), s.SpeedMBps())
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUploadStatsModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		stats     UploadStatsModel
+		wantSpeed float64
+		wantStr   string
+	}{
+		{name: "zero duration", stats: UploadStatsModel{Size: 1000}, wantSpeed: 0, wantStr: "1000 bytes in 0s (0.00 MB/s)"},
+		{name: "negative duration", stats: UploadStatsModel{Size: 1000, Duration: -time.Second}, wantSpeed: 0, wantStr: "1000 bytes in -1s (0.00 MB/s)"},
+		{name: "upload speed", stats: UploadStatsModel{Size: 5e6, Duration: 2 * time.Second}, wantSpeed: 2.5, wantStr: "5000000 bytes in 2s (2.50 MB/s)"},
+		{name: "rounded duration", stats: UploadStatsModel{Size: 1e6, Duration: 1500400 * time.Microsecond}, wantSpeed: 1e6 / 1e6 / 1.5004, wantStr: "1000000 bytes in 1.5s (0.67 MB/s)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.SpeedMBps(); got != tt.wantSpeed {
+				t.Errorf("SpeedMBps() = %v, want %v", got, tt.wantSpeed)
+			}
+			if got := tt.stats.String(); got != tt.wantStr {
+				t.Errorf("String() = %q, want %q", got, tt.wantStr)
+			}
+		})
+	}
+}
+
+func TestUploadStatsModelAdd(t *testing.T) {
+	got := UploadStatsModel{Size: 10, Duration: time.Second}.Add(UploadStatsModel{Size: 5, Duration: 500 * time.Millisecond})
+	if want := (UploadStatsModel{Size: 15, Duration: 1500 * time.Millisecond}); got != want {
+		t.Errorf("Add() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// StatusModel is the machine-readable summary written to the stderr if JSONStatusToStderr is enabled.
+type StatusModel struct {
+	Status     string `json:"status"`
+	StatusCode int    `json:"status_code"`
+	PublicURL  string `json:"public_url"`
+	BuildURL   string `json:"build_url"`
+	Version    string `json:"version"`
+	Error      string `json:"error"`
+}
+
+// lastStatusCode is the status code of the last HTTP response received.
+var lastStatusCode int
+
+func setLastStatusCode(statusCode int) {
+	runStateMutex.Lock()
+	lastStatusCode = statusCode
+	runStateMutex.Unlock()
+}
+
+// stepStartTime is the time the step started at.
+var stepStartTime = time.Now()
+
+// reportStatus reports the final status of the step: writes it to the trace file (if TraceOutputPath is set),
+// pushes the metrics (if MetricsPushgatewayURL is set), sends the Slack notification (if SlackWebhookURL is set),
+// creates the Bitrise build annotation (if EmitAnnotation is enabled)
+// and writes it to the stderr (if JSONStatusToStderr is enabled).
+func reportStatus(status StatusModel) {
+	status.StatusCode = lastStatusCode
+	writeTrace(status)
+	pushMetrics(status)
+	notifySlack(status)
+	emitAnnotation(status)
+	writeJSONStatus(status)
+}
+
+func writeJSONStatus(status StatusModel) {
+	if !configs.JSONStatusToStderr {
+		return
+	}
+	b, err := json.Marshal(status)
+	if err != nil {
+		b = []byte(fmt.Sprintf(`{"status":%q,"error":%q}`, status.Status, err.Error()))
+	}
+	fmt.Fprintln(os.Stderr, string(b))
+}
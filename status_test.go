@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// captureStderr returns what f writes to the stderr.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	pth := filepath.Join(t.TempDir(), "stderr")
+	file, err := os.Create(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stderr
+	os.Stderr = file
+	f()
+	os.Stderr = original
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestWriteJSONStatus(t *testing.T) {
+	status := StatusModel{Status: hockeyAppDeployStatusFailed, StatusCode: 422, PublicURL: "https://public", Version: "1.2.3 (42)", Error: "invalid \"notes\""}
+	tests := []struct {
+		name               string
+		jsonStatusToStderr bool
+		want               string
+	}{
+		{name: "disabled", jsonStatusToStderr: false, want: ""},
+		{name: "enabled", jsonStatusToStderr: true, want: `{"status":"failed","status_code":422,"public_url":"https://public","build_url":"","version":"1.2.3 (42)","error":"invalid \"notes\""}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{JSONStatusToStderr: tt.jsonStatusToStderr})
+
+			got := captureStderr(t, func() { writeJSONStatus(status) })
+
+			if got != tt.want {
+				t.Errorf("stderr = %q, want %q", got, tt.want)
+			}
+			if got != "" {
+				var decoded StatusModel
+				if err := json.Unmarshal([]byte(got), &decoded); err != nil || decoded != status {
+					t.Errorf("decoded status = %+v (error: %v), want %+v", decoded, err, status)
+				}
+			}
+		})
+	}
+}
+
+func TestReportStatusCode(t *testing.T) {
+	original := lastStatusCode
+	t.Cleanup(func() { setLastStatusCode(original) })
+	setConfigs(t, ConfigsModel{JSONStatusToStderr: true})
+
+	setLastStatusCode(201)
+	got := captureStderr(t, func() { reportStatus(StatusModel{Status: hockeyAppDeployStatusSuccess, StatusCode: 500}) })
+
+	var decoded StatusModel
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("invalid status %q: %v", got, err)
+	}
+	if decoded.StatusCode != 201 {
+		t.Errorf("status_code = %d, want the last status code 201", decoded.StatusCode)
+	}
+}
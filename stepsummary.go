@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const githubStepSummaryFileKey = "GITHUB_STEP_SUMMARY"
+
+// stepSummaryMarkdown returns the markdown summary of the deploy, with the install link as a badge.
+func stepSummaryMarkdown(results []DeployResultModel, manifest *ManifestModel, publicURL, buildURL string) string {
+	var b strings.Builder
+	b.WriteString("### HockeyApp deploy\n\n")
+
+	b.WriteString("| Artifact | Status |\n")
+	b.WriteString("| --- | --- |\n")
+	for _, result := range results {
+		fmt.Fprintf(&b, "| `%s` | %s |\n", filepath.Base(result.Artifact.Path), result.Status())
+	}
+	b.WriteString("\n")
+
+	if manifest != nil && (manifest.VersionName != "" || manifest.VersionCode != "") {
+		fmt.Fprintf(&b, "**Version:** %s (%s)\n\n", manifest.VersionName, manifest.VersionCode)
+	}
+	if publicURL != "" {
+		fmt.Fprintf(&b, "[![Install](https://img.shields.io/badge/HockeyApp-Install-blue)](%s)\n\n", publicURL)
+		fmt.Fprintf(&b, "Public URL: %s\n\n", publicURL)
+	}
+	if buildURL != "" {
+		fmt.Fprintf(&b, "Build URL: %s\n\n", buildURL)
+	}
+	return b.String()
+}
+
+// writeStepSummary appends the markdown summary to the GitHub step summary file.
+func writeStepSummary(markdown string) error {
+	pth := os.Getenv(githubStepSummaryFileKey)
+	if pth == "" {
+		return fmt.Errorf("%s is not set", githubStepSummaryFileKey)
+	}
+	return appendToFile(pth, markdown)
+}
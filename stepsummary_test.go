@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStepSummaryMarkdown(t *testing.T) {
+	results := []DeployResultModel{
+		{Artifact: ArtifactModel{Path: "/build/app-arm64.apk"}},
+		{Artifact: ArtifactModel{Path: "/build/app-x86.apk"}, Err: errors.New("upload failed")},
+	}
+	tests := []struct {
+		name      string
+		manifest  *ManifestModel
+		publicURL string
+		buildURL  string
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name:    "artifacts only",
+			want:    []string{"### HockeyApp deploy\n", "| `app-arm64.apk` | success |\n", "| `app-x86.apk` | failed |\n"},
+			wantNot: []string{"**Version:**", "Install", "Public URL", "Build URL"},
+		},
+		{
+			name:     "empty manifest",
+			manifest: &ManifestModel{PackageName: "com.example.app"},
+			wantNot:  []string{"**Version:**"},
+		},
+		{
+			name:      "all details",
+			manifest:  &ManifestModel{VersionName: "1.2.3", VersionCode: "42"},
+			publicURL: "https://public",
+			buildURL:  "https://build",
+			want: []string{
+				"**Version:** 1.2.3 (42)\n",
+				"[![Install](https://img.shields.io/badge/HockeyApp-Install-blue)](https://public)\n",
+				"Public URL: https://public\n",
+				"Build URL: https://build\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stepSummaryMarkdown(results, tt.manifest, tt.publicURL, tt.buildURL)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("stepSummaryMarkdown() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, wantNot := range tt.wantNot {
+				if strings.Contains(got, wantNot) {
+					t.Errorf("stepSummaryMarkdown() = %q, want it not to contain %q", got, wantNot)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteStepSummary(t *testing.T) {
+	t.Run("unset summary file", func(t *testing.T) {
+		t.Setenv(githubStepSummaryFileKey, "")
+		if err := writeStepSummary("summary"); err == nil {
+			t.Error("writeStepSummary() succeeded, want an error")
+		}
+	})
+
+	t.Run("appended summary", func(t *testing.T) {
+		pth := filepath.Join(t.TempDir(), "summary.md")
+		if err := ioutil.WriteFile(pth, []byte("previous\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv(githubStepSummaryFileKey, pth)
+
+		if err := writeStepSummary("summary\n"); err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(pth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != "previous\nsummary\n" {
+			t.Errorf("summary file = %q, want the summary appended", got)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// redact replaces every occurrence of the secrets in s.
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, redactedValue, -1)
+		}
+	}
+	return s
+}
+
+// printableSecret returns the redacted placeholder if the secret is set, an empty string otherwise.
+func printableSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+func (configs ConfigsModel) secrets() []string {
+	return []string{configs.APIToken, configs.HMACSecret}
+}
+
+// summaryLines returns the outputs as key-value lines sorted by key, with the values aligned.
+func summaryLines(outputs map[string]string, secrets []string) []string {
+	keys := make([]string, 0, len(outputs))
+	width := 0
+	for k := range outputs {
+		keys = append(keys, k)
+		if len(k) > width {
+			width = len(k)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, k, redact(outputs[k], secrets)))
+	}
+	return lines
+}
+
+func printSummary(outputs map[string]string, secrets []string) {
+	exported := map[string]string{}
+	for k, v := range outputs {
+		exported[outputKey(k)] = v
+	}
+
+	printNewline()
+	infof("Summary:")
+	for _, line := range summaryLines(exported, secrets) {
+		printf(" %s", line)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		secrets []string
+		want    string
+	}{
+		{name: "no secrets", s: "token", want: "token"},
+		{name: "empty secret", s: "token", secrets: []string{""}, want: "token"},
+		{name: "every occurrence", s: "abc-abc", secrets: []string{"abc"}, want: redactedValue + "-" + redactedValue},
+		{name: "multiple secrets", s: "abc def", secrets: []string{"abc", "def"}, want: redactedValue + " " + redactedValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redact(tt.s, tt.secrets); got != tt.want {
+				t.Errorf("redact() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintableSecret(t *testing.T) {
+	if got := printableSecret(""); got != "" {
+		t.Errorf("printableSecret(\"\") = %q, want empty", got)
+	}
+	if got := printableSecret("secret"); got != redactedValue {
+		t.Errorf("printableSecret() = %q, want %q", got, redactedValue)
+	}
+}
+
+func TestConfigsSecrets(t *testing.T) {
+	c := ConfigsModel{APIToken: "api-token", HMACSecret: "hmac-secret", AppID: "app-id", Notes: "notes"}
+	if got, want := c.secrets(), []string{"api-token", "hmac-secret"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("secrets() = %v, want only the API token and the HMAC secret %v", got, want)
+	}
+}
+
+func TestSummaryLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs map[string]string
+		secrets []string
+		want    []string
+	}{
+		{name: "no outputs", outputs: map[string]string{}, want: []string{}},
+		{
+			name:    "sorted and aligned",
+			outputs: map[string]string{"B_LONG_KEY": "b", "A": "a"},
+			want:    []string{"A           a", "B_LONG_KEY  b"},
+		},
+		{
+			name:    "redacted values",
+			outputs: map[string]string{"URL": "https://example.com?token=abc"},
+			secrets: []string{"abc"},
+			want:    []string{"URL  https://example.com?token=" + redactedValue},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summaryLines(tt.outputs, tt.secrets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summaryLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
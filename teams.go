@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TeamModel ...
+type TeamModel struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// TeamsResponseModel ...
+type TeamsResponseModel struct {
+	Teams []TeamModel `json:"teams"`
+}
+
+// fetchAppTeams returns the teams (distribution groups) the app is shared with.
+func fetchAppTeams(ctx context.Context, client *http.Client, appID string) ([]TeamModel, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/apps/%s/app_teams", hockeyAppAPIURL, appID), nil)
+	if err != nil {
+		return nil, err
+	}
+	setExtraHeaders(request)
+	setExtraQueryParams(request)
+	signRequest(request, time.Now())
+	request.Header.Add("X-HockeyAppToken", configs.APIToken)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+
+	contents, err := readResponseBody(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body, error: %v", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 300 {
+		return nil, statusCodeError{StatusCode: response.StatusCode}
+	}
+
+	var teamsResponse TeamsResponseModel
+	if err := json.Unmarshal(contents, &teamsResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response body, error: %v", err)
+	}
+	return teamsResponse.Teams, nil
+}
+
+// resolveTeamIDs returns the IDs of the teams with the given names (case insensitive),
+// it fails listing every name not matching any of the teams.
+func resolveTeamIDs(teams []TeamModel, names []string) ([]string, error) {
+	var ids, unresolved []string
+	for _, name := range names {
+		found := false
+		for _, team := range teams {
+			if strings.EqualFold(strings.TrimSpace(team.Name), name) {
+				ids = append(ids, strconv.Itoa(team.ID))
+				found = true
+				break
+			}
+		}
+		if !found {
+			unresolved = append(unresolved, name)
+		}
+	}
+	if len(unresolved) > 0 {
+		return nil, fmt.Errorf("no distribution group found with name: %s", strings.Join(unresolved, ", "))
+	}
+	return ids, nil
+}
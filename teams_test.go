@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFetchAppTeams(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantTeams []TeamModel
+		wantErr   bool
+	}{
+		{name: "teams", status: 200, body: `{"teams": [{"id": 1, "name": "QA"}, {"id": 2, "name": "Beta"}]}`, wantTeams: []TeamModel{{ID: 1, Name: "QA"}, {ID: 2, Name: "Beta"}}},
+		{name: "no teams", status: 200, body: `{"teams": []}`, wantTeams: []TeamModel{}},
+		{name: "server error", status: 500, wantErr: true},
+		{name: "invalid body", status: 200, body: `<html>`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{APIToken: "token"})
+			var path, token string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, token = r.URL.Path, r.Header.Get("X-HockeyAppToken")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer ts.Close()
+			setAPIURL(t, &hockeyAppAPIURL, ts.URL+"/api/2")
+
+			teams, err := fetchAppTeams(context.Background(), ts.Client(), "app-id")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchAppTeams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(teams, tt.wantTeams) {
+				t.Errorf("fetchAppTeams() = %v, want %v", teams, tt.wantTeams)
+			}
+			if path != "/api/2/apps/app-id/app_teams" || token != "token" {
+				t.Errorf("request path = %s, token = %q, want the app teams of app-id with the API token", path, token)
+			}
+		})
+	}
+}
+
+func TestResolveTeamIDs(t *testing.T) {
+	teams := []TeamModel{{ID: 1, Name: "QA"}, {ID: 2, Name: " Beta Testers "}}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "exact names", names: []string{"QA"}, want: []string{"1"}},
+		{name: "case insensitive and trimmed", names: []string{"qa", "beta testers"}, want: []string{"1", "2"}},
+		{name: "unknown name", names: []string{"QA", "Alpha", "Gamma"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveTeamIDs(teams, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveTeamIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveTeamIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// tempDir returns the directory of the temporary files: the TempDir if set, the OS default otherwise.
+func tempDir() string {
+	if configs.TempDir != "" {
+		return configs.TempDir
+	}
+	return os.TempDir()
+}
+
+// applyTempDir creates the TempDir if CreateTempDir is set, and checks that it is a writable directory.
+func (configs *ConfigsModel) applyTempDir() error {
+	if configs.TempDir == "" {
+		return nil
+	}
+
+	if configs.CreateTempDir {
+		if err := os.MkdirAll(configs.TempDir, 0755); err != nil {
+			return fmt.Errorf("failed to create TempDir at: %s, error: %v", configs.TempDir, err)
+		}
+	}
+
+	info, err := os.Stat(configs.TempDir)
+	if err != nil {
+		return fmt.Errorf("failed to check if TempDir exist at: %s, error: %v", configs.TempDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("TempDir is not a directory: %s", configs.TempDir)
+	}
+
+	f, err := ioutil.TempFile(configs.TempDir, "hockeyapp-write-check")
+	if err != nil {
+		return fmt.Errorf("TempDir is not writable: %s, error: %v", configs.TempDir, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTempDir(t *testing.T) {
+	setConfigs(t, ConfigsModel{})
+	if got := tempDir(); got != os.TempDir() {
+		t.Errorf("tempDir() = %s, want the OS default %s", got, os.TempDir())
+	}
+
+	setConfigs(t, ConfigsModel{TempDir: "/custom/tmp"})
+	if got := tempDir(); got != "/custom/tmp" {
+		t.Errorf("tempDir() = %s, want the TempDir", got)
+	}
+}
+
+func TestApplyTempDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		tempDir       string
+		createTempDir bool
+		wantErr       bool
+	}{
+		{name: "not set"},
+		{name: "existing directory", tempDir: dir},
+		{name: "missing directory", tempDir: filepath.Join(dir, "missing"), wantErr: true},
+		{name: "created directory", tempDir: filepath.Join(dir, "created", "tmp"), createTempDir: true},
+		{name: "file", tempDir: file, wantErr: true},
+		{name: "file created", tempDir: file, createTempDir: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ConfigsModel{TempDir: tt.tempDir, CreateTempDir: tt.createTempDir}
+			err := c.applyTempDir()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyTempDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil || tt.tempDir == "" {
+				return
+			}
+
+			entries, err := ioutil.ReadDir(tt.tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Name() != "file" {
+					t.Errorf("write check file left in TempDir: %s", entry.Name())
+				}
+			}
+		})
+	}
+}
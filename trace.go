@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TraceModel is the sanitized trace of the step written to the TraceOutputPath.
+type TraceModel struct {
+	StartedAt time.Time           `json:"started_at"`
+	Duration  string              `json:"duration"`
+	Config    ConfigsModel        `json:"config"`
+	Requests  []TraceRequestModel `json:"requests"`
+	Result    StatusModel         `json:"result"`
+}
+
+// TraceRequestModel is a single HTTP request-response cycle of the trace.
+type TraceRequestModel struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	RequestHeaders  map[string]string `json:"request_headers"`
+	ContentLength   int64             `json:"content_length"`
+	StatusCode      int               `json:"status_code,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	Duration        string            `json:"duration"`
+	Error           string            `json:"error,omitempty"`
+}
+
+var (
+	traceRequests []TraceRequestModel
+	traceMutex    sync.Mutex
+)
+
+func traceHeaders(headers http.Header) map[string]string {
+	m := map[string]string{}
+	for name := range headers {
+		value := headers.Get(name)
+		if isSecretHeader(name) {
+			value = redactedValue
+		}
+		m[name] = value
+	}
+	return m
+}
+
+// tracingTransport records the request-response cycles for the trace file.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t tracingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+
+	entry := TraceRequestModel{
+		Method:         request.Method,
+		URL:            request.URL.String(),
+		RequestHeaders: traceHeaders(request.Header),
+		ContentLength:  request.ContentLength,
+		Duration:       time.Since(start).String(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.StatusCode = response.StatusCode
+		entry.ResponseHeaders = traceHeaders(response.Header)
+	}
+
+	traceMutex.Lock()
+	traceRequests = append(traceRequests, entry)
+	traceMutex.Unlock()
+
+	return response, err
+}
+
+// printable returns a copy of the configs with the secret inputs redacted the same way as print() does,
+// including the credentials embedded in the URLs.
+func (configs ConfigsModel) printable() ConfigsModel {
+	printable := configs
+	printable.APIToken = printableSecret(configs.APIToken)
+	printable.HMACSecret = printableSecret(configs.HMACSecret)
+	printable.SlackWebhookURL = printableSecret(configs.SlackWebhookURL)
+	printable.NetworkConfigJSON = printableSecret(configs.NetworkConfigJSON)
+	printable.ProxyURL = printableProxyURL(configs.ProxyURL)
+	printable.ApkURL = printableProxyURL(configs.ApkURL)
+	printable.MetricsPushgatewayURL = printableProxyURL(configs.MetricsPushgatewayURL)
+	printable.ShortenerURL = printableProxyURL(configs.ShortenerURL)
+	if headers, err := parseExtraHeaders(configs.ExtraHeaders); err == nil {
+		printable.ExtraHeaders = printableHeaders(headers)
+	} else {
+		printable.ExtraHeaders = redactedValue
+	}
+	return printable
+}
+
+// writeTrace writes the trace with the final status of the step to the TraceOutputPath,
+// the secrets are redacted.
+func writeTrace(status StatusModel) {
+	if configs.TraceOutputPath == "" {
+		return
+	}
+
+	traceMutex.Lock()
+	trace := TraceModel{
+		StartedAt: stepStartTime,
+		Duration:  time.Since(stepStartTime).String(),
+		Config:    configs.printable(),
+		Requests:  traceRequests,
+		Result:    status,
+	}
+	traceMutex.Unlock()
+
+	b, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		warnf("Failed to marshal the trace, error: %v", err)
+		return
+	}
+	if err := ioutil.WriteFile(configs.TraceOutputPath, []byte(redact(string(b), configs.secrets())), 0600); err != nil {
+		warnf("Failed to write the trace to: %s, error: %v", configs.TraceOutputPath, err)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// Uploader performs the upload of an artifact, so the deploy flow does not depend on the transport.
+type Uploader interface {
+	// Upload uploads the artifact at the given index of the deployed artifacts, the reporter is optional.
+	Upload(ctx context.Context, index int, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error)
+}
+
+// httpUploader uploads the artifacts with the HockeyApp (or App Center) API.
+type httpUploader struct{}
+
+func (httpUploader) Upload(ctx context.Context, _ int, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	return deploy(ctx, artifact, idempotencyKey, reporter)
+}
+
+// packagedUploader replays the packaged requests, the artifact at the given index is uploaded by the request at the same index.
+type packagedUploader struct {
+	requestPackage RequestPackageModel
+}
+
+// artifacts returns the artifacts of the packaged requests, in the order of the requests.
+func (u packagedUploader) artifacts() []ArtifactModel {
+	artifacts := []ArtifactModel{}
+	for _, packaged := range u.requestPackage.Requests {
+		artifacts = append(artifacts, packaged.Artifact)
+	}
+	return artifacts
+}
+
+func (u packagedUploader) Upload(ctx context.Context, index int, _ ArtifactModel, _ string, reporter ProgressReporter) (ResponseModel, error) {
+	if index < 0 || index >= len(u.requestPackage.Requests) {
+		return ResponseModel{}, fmt.Errorf("no packaged request found for artifact %d", index)
+	}
+	return deployPackagedRequest(ctx, u.requestPackage.Requests[index], reporter)
+}
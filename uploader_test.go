@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeUploader records the uploads and responds with the configured response or error of the artifact path.
+type fakeUploader struct {
+	mutex     sync.Mutex
+	responses map[string]ResponseModel
+	errs      map[string]error
+	uploads   []fakeUpload
+}
+
+type fakeUpload struct {
+	Index          int
+	Path           string
+	IdempotencyKey string
+}
+
+func (u *fakeUploader) Upload(_ context.Context, index int, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	u.mutex.Lock()
+	u.uploads = append(u.uploads, fakeUpload{Index: index, Path: artifact.Path, IdempotencyKey: idempotencyKey})
+	u.mutex.Unlock()
+
+	if err := u.errs[artifact.Path]; err != nil {
+		return ResponseModel{}, err
+	}
+	response := u.responses[artifact.Path]
+	if reporter != nil {
+		reporter.OnValidated(artifact)
+		reporter.OnComplete(response)
+	}
+	return response, nil
+}
+
+func TestDeployArtifact(t *testing.T) {
+	uploadErr := errors.New("connection reset")
+	tests := []struct {
+		name           string
+		idempotencyKey string
+		index          int
+		count          int
+		artifact       ArtifactModel
+		wantResponse   ResponseModel
+		wantErr        error
+		wantKey        string
+	}{
+		{
+			name:           "single artifact uses the idempotency key as is",
+			idempotencyKey: "key",
+			index:          0,
+			count:          1,
+			artifact:       ArtifactModel{Type: artifactTypeAPK, Path: "app.apk"},
+			wantResponse:   ResponseModel{ID: 1, PublicURL: "https://rink.hockeyapp.net/apps/1"},
+			wantKey:        "key",
+		},
+		{
+			name:           "multiple artifacts get indexed idempotency keys",
+			idempotencyKey: "key",
+			index:          1,
+			count:          2,
+			artifact:       ArtifactModel{Type: artifactTypeAAB, Path: "app.aab"},
+			wantResponse:   ResponseModel{ID: 2},
+			wantKey:        "key-1",
+		},
+		{
+			name:           "upload error is returned",
+			idempotencyKey: "key",
+			index:          0,
+			count:          1,
+			artifact:       ArtifactModel{Type: artifactTypeAPK, Path: "broken.apk"},
+			wantErr:        uploadErr,
+			wantKey:        "key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{IdempotencyKey: tt.idempotencyKey})
+			uploader := &fakeUploader{
+				responses: map[string]ResponseModel{"app.apk": {ID: 1, PublicURL: "https://rink.hockeyapp.net/apps/1"}, "app.aab": {ID: 2}},
+				errs:      map[string]error{"broken.apk": uploadErr},
+			}
+
+			response, err := deployArtifact(context.Background(), uploader, tt.index, tt.count, tt.artifact, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("deployArtifact() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(response, tt.wantResponse) {
+				t.Errorf("deployArtifact() = %+v, want %+v", response, tt.wantResponse)
+			}
+			want := []fakeUpload{{Index: tt.index, Path: tt.artifact.Path, IdempotencyKey: tt.wantKey}}
+			if !reflect.DeepEqual(uploader.uploads, want) {
+				t.Errorf("uploads = %+v, want %+v", uploader.uploads, want)
+			}
+		})
+	}
+}
+
+func TestPackagedUploader(t *testing.T) {
+	uploader := packagedUploader{requestPackage: RequestPackageModel{Requests: []PackagedRequestModel{{}}}}
+	for _, index := range []int{-1, 1} {
+		if _, err := uploader.Upload(context.Background(), index, ArtifactModel{}, "", nil); err == nil {
+			t.Errorf("Upload(%d) error = nil, want an error for the missing packaged request", index)
+		}
+	}
+}
+
+func TestDeployArtifactsParallelAbort(t *testing.T) {
+	artifacts := []ArtifactModel{{Type: artifactTypeAPK, Path: "app.apk"}, {Type: artifactTypeAPK, Path: "rejected.apk"}}
+	tests := []struct {
+		name             string
+		preUploadCommand string
+		errs             map[string]error
+		wantUploads      int
+	}{
+		{
+			name:        "rejected API token",
+			errs:        map[string]error{"rejected.apk": statusCodeError{StatusCode: http.StatusUnauthorized}},
+			wantUploads: 2,
+		},
+		{
+			name:             "failed pre upload command",
+			preUploadCommand: `test "$` + hookArtifactPathKey + `" != rejected.apk`,
+			wantUploads:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{IdempotencyKey: "key", PreUploadCommand: tt.preUploadCommand})
+			uploader := &fakeUploader{responses: map[string]ResponseModel{"app.apk": {ID: 1}}, errs: tt.errs}
+
+			responseModels, errs := deployArtifacts(context.Background(), uploader, artifacts, true)
+
+			if errs[0] != nil || responseModels[0].ID != 1 {
+				t.Errorf("sibling upload = %+v, %v, want it to finish", responseModels[0], errs[0])
+			}
+			if !isDeployAbortedError(errs[1]) {
+				t.Errorf("error = %v, want a deployAbortedError", errs[1])
+			}
+			if len(uploader.uploads) != tt.wantUploads {
+				t.Errorf("%d uploads, want %d", len(uploader.uploads), tt.wantUploads)
+			}
+		})
+	}
+}
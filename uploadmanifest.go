@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// UploadedFileModel is a file sent in an upload request, its role is the artifact type it was sent as.
+type UploadedFileModel struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	Role   string `json:"role"`
+}
+
+// UploadManifestModel lists every file sent by the step.
+type UploadManifestModel struct {
+	Files []UploadedFileModel `json:"files"`
+}
+
+// uploadedFiles returns the files sent in the upload request of the artifact from their checksums by path,
+// the artifact has the role of its type, the other file is the mapping.
+func uploadedFiles(artifact ArtifactModel, checksums map[string]string) []UploadedFileModel {
+	var files []UploadedFileModel
+	for _, pth := range sortedKeys(checksums) {
+		role := artifactTypeMapping
+		if pth == artifact.Path {
+			role = artifact.Type
+		}
+		size, err := fileSize(pth)
+		if err != nil {
+			warnf("Failed to get the size of %s, error: %v", pth, err)
+		}
+		files = append(files, UploadedFileModel{
+			Name:   filepath.Base(pth),
+			Path:   pth,
+			Size:   int64(size),
+			SHA256: checksums[pth],
+			Role:   role,
+		})
+	}
+	return files
+}
+
+// writeUploadManifest writes the upload manifest as indented JSON to pth.
+func writeUploadManifest(pth string, manifest UploadManifestModel) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(pth, data, 0644)
+}
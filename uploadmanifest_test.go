@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUploadedFiles(t *testing.T) {
+	dir := t.TempDir()
+	apkPath := filepath.Join(dir, "app.apk")
+	mappingPath := filepath.Join(dir, "mapping.txt")
+	if err := ioutil.WriteFile(apkPath, []byte("apk"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(mappingPath, []byte("mapping"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	artifact := ArtifactModel{Type: artifactTypeAPK, Path: apkPath}
+
+	tests := []struct {
+		name      string
+		checksums map[string]string
+		want      []UploadedFileModel
+	}{
+		{name: "no files", checksums: map[string]string{}},
+		{
+			name:      "artifact only",
+			checksums: map[string]string{apkPath: "apk-sha"},
+			want:      []UploadedFileModel{{Name: "app.apk", Path: apkPath, Size: 3, SHA256: "apk-sha", Role: artifactTypeAPK}},
+		},
+		{
+			name:      "artifact and mapping",
+			checksums: map[string]string{mappingPath: "mapping-sha", apkPath: "apk-sha"},
+			want: []UploadedFileModel{
+				{Name: "app.apk", Path: apkPath, Size: 3, SHA256: "apk-sha", Role: artifactTypeAPK},
+				{Name: "mapping.txt", Path: mappingPath, Size: 7, SHA256: "mapping-sha", Role: artifactTypeMapping},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uploadedFiles(artifact, tt.checksums); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("uploadedFiles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteUploadManifest(t *testing.T) {
+	pth := filepath.Join(t.TempDir(), "upload-manifest.json")
+	manifest := UploadManifestModel{Files: []UploadedFileModel{{Name: "app.apk", Path: "/build/app.apk", Size: 3, SHA256: "apk-sha", Role: artifactTypeAPK}}}
+
+	if err := writeUploadManifest(pth, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(pth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got UploadManifestModel
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, manifest) {
+		t.Errorf("written manifest = %+v, want %+v", got, manifest)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// AppVersionModel ...
+type AppVersionModel struct {
+	ID           int    `json:"id"`
+	Version      string `json:"version"`
+	ShortVersion string `json:"shortversion"`
+	AppSize      int64  `json:"appsize"`
+}
+
+// AppVersionsResponseModel ...
+type AppVersionsResponseModel struct {
+	AppVersions []AppVersionModel `json:"app_versions"`
+}
+
+func fetchAppVersions(ctx context.Context, client *http.Client, appID string) ([]AppVersionModel, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/apps/%s/app_versions", hockeyAppAPIURL, appID), nil)
+	if err != nil {
+		return nil, err
+	}
+	setExtraHeaders(request)
+	setExtraQueryParams(request)
+	signRequest(request, time.Now())
+	request.Header.Add("X-HockeyAppToken", configs.APIToken)
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := response.Body.Close(); err != nil {
+			warnf("Failed to close response body, error: %v", err)
+		}
+	}()
+
+	contents, err := readResponseBody(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body, error: %v", err)
+	}
+	if response.StatusCode < 200 || response.StatusCode > 300 {
+		return nil, statusCodeError{StatusCode: response.StatusCode}
+	}
+
+	var versionsResponse AppVersionsResponseModel
+	if err := json.Unmarshal(contents, &versionsResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response body, error: %v", err)
+	}
+	return versionsResponse.AppVersions, nil
+}
+
+// findAppVersion returns the first (latest) version matching the non-empty targets.
+func findAppVersion(versions []AppVersionModel, version, shortVersion string) (AppVersionModel, bool) {
+	for _, v := range versions {
+		if version != "" && v.Version != version {
+			continue
+		}
+		if shortVersion != "" && v.ShortVersion != shortVersion {
+			continue
+		}
+		return v, true
+	}
+	return AppVersionModel{}, false
+}
+
+// targetAppVersion returns the existing version matching TargetVersion and TargetShortVersion,
+// the latest version if none of them is set.
+func targetAppVersion(ctx context.Context, client *http.Client) (AppVersionModel, error) {
+	versions, err := fetchAppVersions(ctx, client, configs.AppID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return AppVersionModel{}, totalTimeoutError(ctx, err)
+		}
+		return AppVersionModel{}, fmt.Errorf("Failed to fetch app versions, error: %v", err)
+	}
+
+	version, ok := findAppVersion(versions, configs.TargetVersion, configs.TargetShortVersion)
+	if !ok {
+		return AppVersionModel{}, fmt.Errorf("no app version found matching version: %q, short version: %q", configs.TargetVersion, configs.TargetShortVersion)
+	}
+	return version, nil
+}
+
+// appVersionURL returns the update URL of the existing version.
+func appVersionURL(version AppVersionModel) string {
+	return fmt.Sprintf("%s/apps/%s/app_versions/%d", hockeyAppAPIURL, configs.AppID, version.ID)
+}
+
+// deployMapping attaches the mapping artifact to the existing version matching TargetVersion and TargetShortVersion,
+// with the update UploadAction the notes and the other fields of the version are updated too.
+func deployMapping(ctx context.Context, artifact ArtifactModel, idempotencyKey string, reporter ProgressReporter) (ResponseModel, error) {
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+
+	version, err := targetAppVersion(ctx, client)
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	printf("Attaching mapping to version: %s (%s), id: %d", version.ShortVersion, version.Version, version.ID)
+
+	fields := map[string]string{}
+	if configs.UploadAction == uploadActionUpdate {
+		_, fields, _ = uploadRequest(artifact)
+	}
+	files := map[string]string{
+		artifact.Field: artifact.Path,
+	}
+	return performRequestWithRetry(ctx, client, multipartRequest("PUT", appVersionURL(version), fields, files, reporter), artifact, idempotencyKey, reporter)
+}
+
+// uploadSeparateMapping attaches the mapping to the uploaded version with the given ID,
+// or to the version matching TargetVersion and TargetShortVersion if no ID is given,
+// as the i-th of the n uploads of the step. Only the mapping of the version is updated.
+func uploadSeparateMapping(ctx context.Context, i, n int, versionID *int) (ResponseModel, error) {
+	artifact := ArtifactModel{Type: artifactTypeMapping, Path: configs.MappingPath, Field: artifactFields[artifactTypeMapping]}
+	printNewline()
+	infof("Performing request (%s: %s)", artifact.Type, artifact.Path)
+
+	key, err := idempotencyKey(i, n)
+	if err != nil {
+		return ResponseModel{}, deployAbortedError{Err: fmt.Errorf("failed to generate idempotency key: %v", err)}
+	}
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+
+	version := AppVersionModel{}
+	if versionID != nil {
+		version.ID = *versionID
+	} else if version, err = targetAppVersion(ctx, client); err != nil {
+		return ResponseModel{}, err
+	}
+	printf("Attaching mapping to version: %d", version.ID)
+
+	files := map[string]string{
+		artifact.Field: artifact.Path,
+	}
+	reporter := newLogReporter()
+	responseModel, err := performRequestWithRetry(ctx, client, multipartRequest("PUT", appVersionURL(version), map[string]string{}, files, reporter), artifact, key, reporter)
+	if isAuthError(err) {
+		return ResponseModel{}, deployAbortedError{Err: fmt.Errorf("Hockeyapp mapping upload failed (%s): %v", artifact.Path, err)}
+	}
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	if responseModel.ID == 0 {
+		responseModel.ID = version.ID
+	}
+	return responseModel, nil
+}
+
+// deployNotes updates the notes of the existing version matching TargetVersion and TargetShortVersion,
+// without uploading any artifact.
+func deployNotes(ctx context.Context) (ResponseModel, error) {
+	client, err := sharedHTTPClient()
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to create HTTP client, error: %v", err)
+	}
+
+	version, err := targetAppVersion(ctx, client)
+	if err != nil {
+		return ResponseModel{}, err
+	}
+	printf("Updating the notes of version: %s (%s), id: %d", version.ShortVersion, version.Version, version.ID)
+
+	key, err := idempotencyKey(0, 1)
+	if err != nil {
+		return ResponseModel{}, fmt.Errorf("Failed to generate idempotency key, error: %v", err)
+	}
+	fields := map[string]string{
+		"notes":      configs.releaseNotes(),
+		"notes_type": configs.NotesType,
+	}
+	return performRequestWithRetry(ctx, client, multipartRequest("PUT", appVersionURL(version), fields, nil, nil), ArtifactModel{}, key, nil)
+}
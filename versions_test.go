@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// hockeyAppServer fakes the app versions endpoints of the HockeyApp API,
+// it lists the versions and records the uploads.
+type hockeyAppServer struct {
+	mutex    sync.Mutex
+	versions []AppVersionModel
+	uploads  []hockeyAppTestUpload
+}
+
+type hockeyAppTestUpload struct {
+	method string
+	path   string
+	fields map[string]string
+	files  map[string]string
+}
+
+// newHockeyAppServer starts the fake server and points the HockeyApp API URL at it for the duration of the test.
+func newHockeyAppServer(t *testing.T, versions []AppVersionModel) *hockeyAppServer {
+	server := &hockeyAppServer{versions: versions}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	setAPIURL(t, &hockeyAppAPIURL, ts.URL+"/api/2")
+	return server
+}
+
+func (s *hockeyAppServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r.Method == "GET" {
+		if err := json.NewEncoder(w).Encode(AppVersionsResponseModel{AppVersions: s.versions}); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	upload := hockeyAppTestUpload{method: r.Method, path: r.URL.Path, fields: map[string]string{}, files: map[string]string{}}
+	for name, values := range r.MultipartForm.Value {
+		upload.fields[name] = values[0]
+	}
+	for name, files := range r.MultipartForm.File {
+		upload.files[name] = filepath.Base(files[0].Filename)
+	}
+	s.uploads = append(s.uploads, upload)
+	if _, err := w.Write([]byte(`{"id": 7, "public_url": "https://rink.hockeyapp.net/apps/app-id/app_versions/7"}`)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestFindAppVersion(t *testing.T) {
+	versions := []AppVersionModel{
+		{ID: 3, Version: "43", ShortVersion: "1.3.0"},
+		{ID: 2, Version: "42", ShortVersion: "1.2.0"},
+		{ID: 1, Version: "41", ShortVersion: "1.2.0"},
+	}
+	tests := []struct {
+		name         string
+		version      string
+		shortVersion string
+		wantID       int
+		wantFound    bool
+	}{
+		{name: "latest", wantID: 3, wantFound: true},
+		{name: "version", version: "41", wantID: 1, wantFound: true},
+		{name: "latest short version", shortVersion: "1.2.0", wantID: 2, wantFound: true},
+		{name: "both", version: "41", shortVersion: "1.2.0", wantID: 1, wantFound: true},
+		{name: "not matching both", version: "43", shortVersion: "1.2.0"},
+		{name: "not found", version: "44"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := findAppVersion(versions, tt.version, tt.shortVersion)
+			if got.ID != tt.wantID || found != tt.wantFound {
+				t.Errorf("findAppVersion() = %d, %v, want %d, %v", got.ID, found, tt.wantID, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestDeployMapping(t *testing.T) {
+	versions := []AppVersionModel{{ID: 8, Version: "43", ShortVersion: "1.3.0"}, {ID: 7, Version: "42", ShortVersion: "1.2.0"}}
+	tests := []struct {
+		name          string
+		targetVersion string
+		uploadAction  string
+		wantPath      string
+		wantNotes     bool
+		wantErr       bool
+	}{
+		{name: "target version", targetVersion: "42", wantPath: "/api/2/apps/app-id/app_versions/7"},
+		{name: "latest version", wantPath: "/api/2/apps/app-id/app_versions/8"},
+		{name: "update action", targetVersion: "42", uploadAction: uploadActionUpdate, wantPath: "/api/2/apps/app-id/app_versions/7", wantNotes: true},
+		{name: "no matching version", targetVersion: "44", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{AppID: "app-id", APIToken: "token", TargetVersion: tt.targetVersion, UploadAction: tt.uploadAction, Notes: "notes"})
+			server := newHockeyAppServer(t, versions)
+
+			artifact := ArtifactModel{Type: artifactTypeMapping, Path: "testdata/output-metadata.json", Field: artifactFields[artifactTypeMapping]}
+			_, err := deployMapping(context.Background(), artifact, "key", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deployMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(server.uploads) != 0 {
+					t.Errorf("uploads = %v, want none", server.uploads)
+				}
+				return
+			}
+			if len(server.uploads) != 1 {
+				t.Fatalf("uploads = %v, want 1", server.uploads)
+			}
+			upload := server.uploads[0]
+			if upload.method != "PUT" || upload.path != tt.wantPath || upload.files["dsym"] != "output-metadata.json" {
+				t.Errorf("upload = %+v, want the mapping PUT to %s", upload, tt.wantPath)
+			}
+			if _, ok := upload.fields["notes"]; ok != tt.wantNotes {
+				t.Errorf("upload fields = %v, want notes: %v", upload.fields, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestDeployNotes(t *testing.T) {
+	setConfigs(t, ConfigsModel{AppID: "app-id", APIToken: "token", TargetShortVersion: "1.2.0", Notes: "Fixed the crash", NotesType: notesTypeText})
+	server := newHockeyAppServer(t, []AppVersionModel{{ID: 8, Version: "43", ShortVersion: "1.3.0"}, {ID: 7, Version: "42", ShortVersion: "1.2.0"}})
+
+	if _, err := deployNotes(context.Background()); err != nil {
+		t.Fatalf("deployNotes() error = %v", err)
+	}
+	if len(server.uploads) != 1 {
+		t.Fatalf("uploads = %v, want 1", server.uploads)
+	}
+	upload := server.uploads[0]
+	if upload.method != "PUT" || upload.path != "/api/2/apps/app-id/app_versions/7" || len(upload.files) != 0 {
+		t.Errorf("upload = %+v, want the notes only PUT to version 7", upload)
+	}
+	if upload.fields["notes"] != "Fixed the crash" || upload.fields["notes_type"] != notesTypeText {
+		t.Errorf("upload fields = %v, want the notes", upload.fields)
+	}
+}
+
+func TestUploadSeparateMapping(t *testing.T) {
+	uploadedID := 9
+	tests := []struct {
+		name      string
+		versionID *int
+		wantPath  string
+	}{
+		{name: "uploaded version", versionID: &uploadedID, wantPath: "/api/2/apps/app-id/app_versions/9"},
+		{name: "target version", wantPath: "/api/2/apps/app-id/app_versions/7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigs(t, ConfigsModel{AppID: "app-id", APIToken: "token", TargetVersion: "42", MappingPath: "testdata/output-metadata.json", Notes: "notes"})
+			server := newHockeyAppServer(t, []AppVersionModel{{ID: 7, Version: "42", ShortVersion: "1.2.0"}})
+
+			if _, err := uploadSeparateMapping(context.Background(), 1, 2, tt.versionID); err != nil {
+				t.Fatalf("uploadSeparateMapping() error = %v", err)
+			}
+			if len(server.uploads) != 1 {
+				t.Fatalf("uploads = %v, want 1", server.uploads)
+			}
+			upload := server.uploads[0]
+			if upload.method != "PUT" || upload.path != tt.wantPath || upload.files["dsym"] != "output-metadata.json" || len(upload.fields) != 0 {
+				t.Errorf("upload = %+v, want only the mapping PUT to %s", upload, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestUploadSeparateMappingRejectedToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+	setAPIURL(t, &hockeyAppAPIURL, ts.URL+"/api/2")
+	setConfigs(t, ConfigsModel{AppID: "app-id", APIToken: "token", MappingPath: "testdata/output-metadata.json"})
+	versionID := 9
+
+	_, err := uploadSeparateMapping(context.Background(), 1, 2, &versionID)
+	if !isDeployAbortedError(err) {
+		t.Errorf("uploadSeparateMapping() error = %v, want a deployAbortedError", err)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bitrise-io/depman/pathutil"
+)
+
+// resolvePath joins relative paths to dir, absolute, empty and standard input (-) paths are returned unchanged.
+func resolvePath(dir, pth string) string {
+	if dir == "" || pth == "" || pth == stdinPath || filepath.IsAbs(pth) {
+		return pth
+	}
+	return filepath.Join(dir, pth)
+}
+
+func resolvePaths(dir string, pths []string) []string {
+	resolved := []string{}
+	for _, pth := range pths {
+		resolved = append(resolved, resolvePath(dir, pth))
+	}
+	return resolved
+}
+
+// applyWorkingDir checks the WorkingDir and resolves the relative input paths against it.
+func (configs *ConfigsModel) applyWorkingDir() error {
+	if configs.WorkingDir == "" {
+		return nil
+	}
+
+	info, err := os.Stat(configs.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("failed to check if WorkingDir exist at: %s, error: %v", configs.WorkingDir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("workingDir is not a directory: %s", configs.WorkingDir)
+	}
+
+	configs.ApkPath = resolvePaths(configs.WorkingDir, configs.ApkPath)
+	configs.ApkPathCandidates = resolvePaths(configs.WorkingDir, configs.ApkPathCandidates)
+	configs.AabPath = resolvePaths(configs.WorkingDir, configs.AabPath)
+	configs.MappingPath = resolvePath(configs.WorkingDir, configs.MappingPath)
+	configs.DotenvPath = resolvePath(configs.WorkingDir, configs.DotenvPath)
+	configs.CACertPath = resolvePath(configs.WorkingDir, configs.CACertPath)
+	configs.UnixSocketPath = resolvePath(configs.WorkingDir, configs.UnixSocketPath)
+	configs.LogFilePath = resolvePath(configs.WorkingDir, configs.LogFilePath)
+	configs.AppIDPath = resolvePath(configs.WorkingDir, configs.AppIDPath)
+	configs.LockFilePath = resolvePath(configs.WorkingDir, configs.LockFilePath)
+	configs.TraceOutputPath = resolvePath(configs.WorkingDir, configs.TraceOutputPath)
+	configs.CredentialsFile = resolvePath(configs.WorkingDir, configs.CredentialsFile)
+	configs.OutputMetadataPath = resolvePath(configs.WorkingDir, configs.OutputMetadataPath)
+	configs.TempDir = resolvePath(configs.WorkingDir, configs.TempDir)
+	configs.InstallHTMLPath = resolvePath(configs.WorkingDir, configs.InstallHTMLPath)
+	configs.UploadManifestPath = resolvePath(configs.WorkingDir, configs.UploadManifestPath)
+	configs.PackageToPath = resolvePath(configs.WorkingDir, configs.PackageToPath)
+	configs.UploadFromPackage = resolvePath(configs.WorkingDir, configs.UploadFromPackage)
+	return nil
+}
+
+// firstExistingPath returns the first existing path of the candidates.
+func firstExistingPath(candidates []string) (string, error) {
+	for _, pth := range candidates {
+		if exist, err := pathutil.IsPathExists(pth); err != nil {
+			return "", fmt.Errorf("failed to check if path exist at: %s, error: %v", pth, err)
+		} else if exist {
+			return pth, nil
+		}
+	}
+	return "", fmt.Errorf("none of the ApkPathCandidates exist: %s", strings.Join(candidates, ", "))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		dir  string
+		pth  string
+		want string
+	}{
+		{dir: "", pth: "app.apk", want: "app.apk"},
+		{dir: "/work", pth: "", want: ""},
+		{dir: "/work", pth: stdinPath, want: stdinPath},
+		{dir: "/work", pth: "/build/app.apk", want: "/build/app.apk"},
+		{dir: "/work", pth: "build/app.apk", want: "/work/build/app.apk"},
+		{dir: "/work", pth: "../app.apk", want: "/app.apk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir+" "+tt.pth, func(t *testing.T) {
+			if got := resolvePath(tt.dir, tt.pth); got != tt.want {
+				t.Errorf("resolvePath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		workingDir string
+		want       ConfigsModel
+		wantErr    bool
+	}{
+		{
+			name: "not set",
+			want: ConfigsModel{ApkPath: []string{"app.apk"}, MappingPath: "mapping.txt", PackageToPath: "package.zip", UploadFromPackage: "upload.zip"},
+		},
+		{
+			name:       "resolved paths",
+			workingDir: dir,
+			want: ConfigsModel{
+				WorkingDir:        dir,
+				ApkPath:           []string{filepath.Join(dir, "app.apk")},
+				MappingPath:       filepath.Join(dir, "mapping.txt"),
+				PackageToPath:     filepath.Join(dir, "package.zip"),
+				UploadFromPackage: filepath.Join(dir, "upload.zip"),
+			},
+		},
+		{name: "missing directory", workingDir: filepath.Join(dir, "missing"), wantErr: true},
+		{name: "file", workingDir: file, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ConfigsModel{WorkingDir: tt.workingDir, ApkPath: []string{"app.apk"}, MappingPath: "mapping.txt", PackageToPath: "package.zip", UploadFromPackage: "upload.zip"}
+			err := c.applyWorkingDir()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyWorkingDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			tt.want.WorkingDir = tt.workingDir
+			if tt.workingDir != "" {
+				tt.want.ApkPathCandidates = []string{}
+				tt.want.AabPath = []string{}
+			}
+			if !reflect.DeepEqual(c, tt.want) {
+				t.Errorf("applyWorkingDir() configs = %+v, want %+v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstExistingPath(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "app.apk")
+	if err := ioutil.WriteFile(existing, []byte("apk"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.apk")
+
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+		wantErr    bool
+	}{
+		{name: "no candidates", wantErr: true},
+		{name: "first existing", candidates: []string{missing, existing, dir}, want: existing},
+		{name: "none existing", candidates: []string{missing}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := firstExistingPath(tt.candidates)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("firstExistingPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("firstExistingPath() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"strings"
+)
+
+const (
+	zipAlignment           = 4
+	nativeLibraryAlignment = 4096
+)
+
+// checkZipAlign returns an error listing the uncompressed entries of the APK whose data is not aligned:
+// shared libraries to 4096 bytes (memory page), every other entry to 4 bytes, as zipalign does.
+func checkZipAlign(apkPath string) error {
+	r, err := zip.OpenReader(apkPath)
+	if err != nil {
+		return fmt.Errorf("failed to open APK (%s), error: %v", apkPath, err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			warnf("Failed to close APK (%s), error: %v", apkPath, err)
+		}
+	}()
+
+	var misaligned []string
+	for _, f := range r.File {
+		if f.Method != zip.Store {
+			continue
+		}
+
+		offset, err := f.DataOffset()
+		if err != nil {
+			return fmt.Errorf("failed to read the data offset of %s in APK (%s), error: %v", f.Name, apkPath, err)
+		}
+		alignment := int64(zipAlignment)
+		if strings.HasSuffix(f.Name, ".so") {
+			alignment = nativeLibraryAlignment
+		}
+		if offset%alignment != 0 {
+			misaligned = append(misaligned, fmt.Sprintf("%s (offset: %d, required alignment: %d)", f.Name, offset, alignment))
+		}
+	}
+
+	if len(misaligned) > 0 {
+		return fmt.Errorf("APK (%s) is not zipaligned, misaligned entries: %s", apkPath, strings.Join(misaligned, ", "))
+	}
+	return nil
+}
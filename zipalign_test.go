@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestCheckZipAlign(t *testing.T) {
+	tests := []struct {
+		name    string
+		apkPath string
+		wantErr bool
+	}{
+		{name: "aligned", apkPath: "testdata/aligned.apk"},
+		{name: "misaligned", apkPath: "testdata/unaligned.apk", wantErr: true},
+		{name: "missing", apkPath: "testdata/missing.apk", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkZipAlign(tt.apkPath); (err != nil) != tt.wantErr {
+				t.Errorf("checkZipAlign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}